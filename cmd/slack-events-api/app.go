@@ -1,23 +1,122 @@
 package main
 
 import (
+	"context"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/himura467/slack-review-request-bot/internal/config"
+	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
 	"github.com/himura467/slack-review-request-bot/internal/interface/rest"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
 )
 
 type app struct {
-	server *rest.Server
+	server       *rest.Server
+	config       *config.SlackConfig
+	serverConfig *config.ServerConfig
+	jobQueue     *infrastructure.WorkerPool
+	kvStore      infrastructure.KVStore
+	tracing      *infrastructure.Tracing
+	health       *usecase.HealthUsecaseImpl
 }
 
-func newApp(server *rest.Server) *app {
+func newApp(
+	server *rest.Server,
+	config *config.SlackConfig,
+	serverConfig *config.ServerConfig,
+	jobQueue *infrastructure.WorkerPool,
+	kvStore infrastructure.KVStore,
+	tracing *infrastructure.Tracing,
+	health *usecase.HealthUsecaseImpl,
+) *app {
 	return &app{
-		server: server,
+		server:       server,
+		config:       config,
+		serverConfig: serverConfig,
+		jobQueue:     jobQueue,
+		kvStore:      kvStore,
+		tracing:      tracing,
+		health:       health,
 	}
 }
 
 func (a *app) Run() {
-	if err := a.server.Run(); err != nil {
-		slog.Error("failed to run server", "error", err)
+	defer a.closeStore()
+	if err := a.jobQueue.Start(); err != nil {
+		slog.Error("failed to start job queue", "error", err)
+		return
+	}
+	// Cloud Run sends SIGTERM before stopping an instance
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go a.reloadSigningSecrets(ctx)
+	go a.health.MonitorSlackAuth(ctx)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.server.Run()
+	}()
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			slog.Error("failed to run server", "error", err)
+		}
+	case <-ctx.Done():
+		slog.Info("shutdown signal received")
+	}
+	a.shutdown()
+}
+
+// shutdown stops accepting requests, then waits for in-flight requests and background jobs within the shutdown timeout
+func (a *app) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.serverConfig.ShutdownTimeout)
+	defer cancel()
+	if err := a.server.Shutdown(ctx); err != nil {
+		slog.Error("failed to shut down server", "error", err)
+	}
+	// Requests may have enqueued jobs until the server stopped, so drain the queue afterwards
+	if err := a.jobQueue.Shutdown(ctx); err != nil {
+		slog.Error("failed to drain job queue", "error", err)
+	}
+	// Flush the spans of the drained jobs
+	if err := a.tracing.Shutdown(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	slog.Info("shutdown complete")
+}
+
+func (a *app) closeStore() {
+	if err := a.kvStore.Close(); err != nil {
+		slog.Error("failed to close store", "error", err)
+	}
+}
+
+// reloadSigningSecrets reloads the signing secrets on SIGHUP and, if configured, at a fixed interval
+func (a *app) reloadSigningSecrets(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	var tick <-chan time.Time
+	if a.config.SigningSecretReloadInterval > 0 {
+		ticker := time.NewTicker(a.config.SigningSecretReloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+		}
+		reloadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := a.config.SigningSecrets.Reload(reloadCtx); err != nil {
+			slog.Error("failed to reload signing secrets", "error", err)
+		}
+		cancel()
 	}
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
+)
+
+// runExport writes the reviews in the store at STORE_PATH to stdout
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := flags.String("from", "", "export reviews requested at or after this RFC 3339 time")
+	to := flags.String("to", "", "export reviews requested before this RFC 3339 time")
+	formatText := flags.String("format", "csv", "csv, json or ndjson")
+	workspaceURL := flags.String("workspace-url", "", "workspace URL such as https://example.slack.com/ to include thread permalinks")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	format, err := model.ParseExportFormat(*formatText)
+	if err != nil {
+		return err
+	}
+	query := model.ExportQuery{Format: format}
+	if query.From, err = parseTimeFlag("from", *from); err != nil {
+		return err
+	}
+	if query.To, err = parseTimeFlag("to", *to); err != nil {
+		return err
+	}
+	kv, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	// Slack is not needed because the workspace URL is given
+	exporter := usecase.NewExportUsecase(infrastructure.NewReviewStore(kv), nil)
+	return exporter.Export(query, *workspaceURL, os.Stdout)
+}
+
+// parseTimeFlag parses the RFC 3339 time given to the flag, or returns the zero time if it was not given
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("-%s must be an RFC 3339 time", name)
+	}
+	return t, nil
+}
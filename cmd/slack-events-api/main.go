@@ -1,6 +1,69 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/himura467/slack-review-request-bot/internal/config"
+	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
+	"github.com/himura467/slack-review-request-bot/internal/logging"
+)
+
 func main() {
-	app := initializeApp()
+	logConfig, err := config.NewLogConfig()
+	if err != nil {
+		slog.Error("failed to load log config", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(slog.New(logging.NewHandler(os.Stdout, logConfig.Level)))
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	app, err := initializeApp()
+	if err != nil {
+		slog.Error("failed to initialize app", "error", err)
+		os.Exit(1)
+	}
 	app.Run()
 }
+
+// runCommand runs a subcommand instead of the server
+func runCommand(name string, args []string) error {
+	switch name {
+	case "stats":
+		return runStats(args)
+	case "export":
+		return runExport(args)
+	default:
+		return fmt.Errorf("unknown command %q; available: stats, export", name)
+	}
+}
+
+// openStore opens the store at STORE_PATH for reading.
+// The commands work offline only: a running server holds the file, and its data is available from the API instead.
+func openStore() (infrastructure.KVStore, error) {
+	storeConfig, err := config.NewStoreConfig()
+	if err != nil {
+		return nil, err
+	}
+	if storeConfig.Path == "" {
+		return nil, errors.New("STORE_PATH must point to the store of the bot")
+	}
+	kv, err := infrastructure.NewReadOnlyBoltKVStore(storeConfig.Path)
+	if errors.Is(err, infrastructure.ErrStoreLocked) {
+		return nil, fmt.Errorf("%s is in use by a running server: stop it, point STORE_PATH at a copy of the file, "+
+			"or use GET /api/v1/stats and GET /api/reviews/export of the server instead", storeConfig.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	return kv, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
+)
+
+// runStats prints the review statistics computed from the store at STORE_PATH
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	periodText := flags.String("period", "", "period ending now, e.g. 24h, 7d or 4w (default 7d)")
+	asJSON := flags.Bool("json", false, "print the statistics as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	period, err := model.ParseStatsPeriod(*periodText, time.Now())
+	if err != nil {
+		return err
+	}
+	kv, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	stats, err := usecase.NewStatsUsecase(infrastructure.NewAuditStore(kv, 0)).Compute(period)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	return printStats(os.Stdout, stats)
+}
+
+// printStats writes the statistics as a human readable report
+func printStats(w io.Writer, stats *model.ReviewStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Period\t%s - %s\n", stats.Period.From.Format(time.DateTime), stats.Period.To.Format(time.DateTime))
+	fmt.Fprintf(tw, "Requested\t%d\n", stats.Requested)
+	fmt.Fprintf(tw, "Assigned\t%d\n", stats.Assigned)
+	fmt.Fprintf(tw, "Completed\t%d\n", stats.Completed)
+	fmt.Fprintf(tw, "Time to first response\t%s\n", durationSummary(stats.TimeToFirstResponse))
+	fmt.Fprintf(tw, "Time to completion\t%s\n", durationSummary(stats.TimeToCompletion))
+	fmt.Fprintf(tw, "Reassign rate\t%.1f%% (%d/%d)\n", 100*stats.ReassignRate, stats.Reassigned, stats.Assigned)
+	fmt.Fprintf(tw, "Urgent hit rate\t%.1f%% (%d/%d)\n", 100*stats.UrgentHitRate, stats.UrgentHits, stats.UrgentAttempts)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Member\tName\tRequested\tAssigned\tCompleted")
+	for _, p := range stats.People {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", p.MemberID, p.DisplayName, p.Requested, p.Assigned, p.Completed)
+	}
+	return tw.Flush()
+}
+
+// durationSummary formats the median and 90th percentile, or a dash without any data
+func durationSummary(s model.DurationStats) string {
+	if s.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("median %s, p90 %s (n=%d)", s.Median.Round(time.Second), s.P90.Round(time.Second), s.Count)
+}
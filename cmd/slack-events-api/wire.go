@@ -4,32 +4,189 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/google/wire"
 	"github.com/himura467/slack-review-request-bot/internal/config"
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
 	"github.com/himura467/slack-review-request-bot/internal/interface/rest"
+	"github.com/himura467/slack-review-request-bot/internal/interface/rest/controller"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
 )
 
 func provideOAuthToken(cfg *config.SlackConfig) model.OAuthToken {
 	return cfg.OAuthToken
 }
 
-func provideSigningSecret(cfg *config.SlackConfig) model.SigningSecret {
-	return cfg.SigningSecret
+func provideSigningSecretRepository(cfg *config.SlackConfig) repository.SigningSecretRepository {
+	return cfg.SigningSecrets
 }
 
 func provideReviewerMap(cfg *config.SlackConfig) model.ReviewerMap {
 	return cfg.ReviewerMap
 }
 
-func initializeApp() *app {
+func provideCodeHostLogins(cfg *config.SlackConfig) model.CodeHostLogins {
+	return cfg.Logins
+}
+
+func provideKVStore(cfg *config.StoreConfig) (infrastructure.KVStore, error) {
+	if cfg.Path == "" {
+		return infrastructure.NewMemoryKVStore(), nil
+	}
+	return infrastructure.NewBoltKVStore(cfg.Path)
+}
+
+func provideWorkerPoolOptions(cfg *config.JobQueueConfig) infrastructure.WorkerPoolOptions {
+	return infrastructure.WorkerPoolOptions{
+		Workers:     cfg.Workers,
+		QueueSize:   cfg.QueueSize,
+		JobTimeout:  cfg.JobTimeout,
+		MaxAttempts: cfg.MaxAttempts,
+		RetryDelay:  cfg.RetryDelay,
+	}
+}
+
+func provideIdempotencyRepository(kv infrastructure.KVStore, cfg *config.IdempotencyConfig) repository.IdempotencyRepository {
+	return infrastructure.NewIdempotencyStore(kv, cfg.TTL)
+}
+
+func provideAuditRepository(kv infrastructure.KVStore, cfg *config.AuditConfig) repository.AuditRepository {
+	return infrastructure.NewAuditStore(kv, cfg.Retention)
+}
+
+func provideSlackUsecaseOptions(cfg *config.IdempotencyConfig, languageCfg *config.LanguageConfig) usecase.SlackUsecaseOptions {
+	return usecase.SlackUsecaseOptions{
+		FastAck:          cfg.FastAck,
+		Language:         languageCfg.Default,
+		ChannelLanguages: languageCfg.Channels,
+		UserLocale:       languageCfg.UserLocale,
+	}
+}
+
+func provideMetricsHandler(metrics *infrastructure.Metrics) rest.MetricsHandler {
+	return metrics.Handler()
+}
+
+func provideTracingOptions(cfg *config.TracingConfig) infrastructure.TracingOptions {
+	return infrastructure.TracingOptions{
+		Enabled:     cfg.Enabled,
+		ServiceName: cfg.ServiceName,
+	}
+}
+
+func provideHealthUsecaseOptions(slackCfg *config.SlackConfig, healthCfg *config.HealthConfig) usecase.HealthUsecaseOptions {
+	return usecase.HealthUsecaseOptions{
+		ReviewerMapError:     slackCfg.ReviewerMapError,
+		AuthTestInterval:     healthCfg.AuthTestInterval,
+		AuthTestRetryBackoff: healthCfg.AuthTestRetryBackoff,
+		AuthTestMaxAge:       healthCfg.AuthTestMaxAge,
+	}
+}
+
+func provideSlackSignInOptions(cfg *config.DashboardConfig) infrastructure.SlackSignInOptions {
+	return infrastructure.SlackSignInOptions{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.BaseURL + "/dashboard/callback",
+		TeamID:       cfg.TeamID,
+	}
+}
+
+func provideDashboardUsecaseOptions(cfg *config.DashboardConfig, languageCfg *config.LanguageConfig) usecase.DashboardUsecaseOptions {
+	return usecase.DashboardUsecaseOptions{
+		TeamID:     cfg.TeamID,
+		Language:   languageCfg.Default,
+		UserLocale: languageCfg.UserLocale,
+	}
+}
+
+func provideSessionOptions(cfg *config.DashboardConfig) controller.SessionOptions {
+	return controller.SessionOptions{
+		Secret: []byte(cfg.SessionSecret),
+		TTL:    cfg.SessionTTL,
+		Secure: strings.HasPrefix(cfg.BaseURL, "https://"),
+		TeamID: cfg.TeamID,
+	}
+}
+
+func provideGitHubOptions(cfg *config.GitHubConfig) infrastructure.GitHubOptions {
+	return infrastructure.GitHubOptions{
+		Token:         cfg.Token,
+		APIURL:        cfg.APIURL,
+		WebhookSecret: cfg.WebhookSecret,
+	}
+}
+
+func provideGitLabOptions(cfg *config.GitLabConfig) infrastructure.GitLabOptions {
+	return infrastructure.GitLabOptions{
+		Token:        cfg.Token,
+		URL:          cfg.URL,
+		WebhookToken: cfg.WebhookToken,
+	}
+}
+
+func provideAPITokens(cfg *config.APIConfig) []rest.APIToken {
+	tokens := make([]rest.APIToken, len(cfg.Tokens))
+	for i, token := range cfg.Tokens {
+		tokens[i] = rest.APIToken{Name: token.Name, Token: token.Token, Scopes: token.Scopes}
+	}
+	return tokens
+}
+
+func provideServerOptions(cfg *config.ServerConfig, apiCfg *config.APIConfig, dashboardCfg *config.DashboardConfig) rest.ServerOptions {
+	return rest.ServerOptions{
+		Port:         cfg.Port,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		MaxBodyBytes: int64(cfg.MaxBodyBytes),
+		APITokens:    provideAPITokens(apiCfg),
+		// Only the administrators may manage the reviewers with a dashboard session
+		AdminMemberIDs: apiCfg.AdminMemberIDs,
+		// Serve the dashboard only when Sign in with Slack is configured
+		DashboardEnabled: dashboardCfg.Enabled(),
+	}
+}
+
+func initializeApp() (*app, error) {
 	wire.Build(
 		config.NewSlackConfig,
+		config.NewStoreConfig,
+		config.NewJobQueueConfig,
+		config.NewServerConfig,
+		config.NewIdempotencyConfig,
+		config.NewAuditConfig,
+		config.NewTracingConfig,
+		config.NewHealthConfig,
+		config.NewAPIConfig,
+		config.NewDashboardConfig,
+		config.NewGitHubConfig,
+		config.NewGitLabConfig,
+		config.NewLanguageConfig,
+		i18n.NewCatalog,
 		rest.Set,
 		provideOAuthToken,
-		provideSigningSecret,
+		provideSigningSecretRepository,
 		provideReviewerMap,
+		provideCodeHostLogins,
+		provideKVStore,
+		provideWorkerPoolOptions,
+		provideIdempotencyRepository,
+		provideAuditRepository,
+		provideSlackUsecaseOptions,
+		provideMetricsHandler,
+		provideTracingOptions,
+		provideHealthUsecaseOptions,
+		provideSlackSignInOptions,
+		provideDashboardUsecaseOptions,
+		provideSessionOptions,
+		provideGitHubOptions,
+		provideGitLabOptions,
+		provideServerOptions,
 		newApp,
 	)
-	return &app{}
+	return &app{}, nil
 }
@@ -9,25 +9,122 @@ package main
 import (
 	"github.com/himura467/slack-review-request-bot/internal/config"
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
 	"github.com/himura467/slack-review-request-bot/internal/infrastructure"
 	"github.com/himura467/slack-review-request-bot/internal/interface/rest"
 	"github.com/himura467/slack-review-request-bot/internal/interface/rest/controller"
 	"github.com/himura467/slack-review-request-bot/internal/usecase"
+	"strings"
 )
 
 // Injectors from wire.go:
 
-func initializeApp() *app {
-	slackConfig := config.NewSlackConfig()
+func initializeApp() (*app, error) {
+	slackConfig, err := config.NewSlackConfig()
+	if err != nil {
+		return nil, err
+	}
 	oAuthToken := provideOAuthToken(slackConfig)
-	signingSecret := provideSigningSecret(slackConfig)
-	client := infrastructure.NewClient(oAuthToken, signingSecret)
+	signingSecretRepository := provideSigningSecretRepository(slackConfig)
+	storeConfig, err := config.NewStoreConfig()
+	if err != nil {
+		return nil, err
+	}
+	kvStore, err := provideKVStore(storeConfig)
+	if err != nil {
+		return nil, err
+	}
+	reviewStore := infrastructure.NewReviewStore(kvStore)
+	metrics := infrastructure.NewMetrics(reviewStore)
+	client := infrastructure.NewClient(oAuthToken, signingSecretRepository, metrics)
+	resilientClient := infrastructure.NewResilientClient(client)
+	instrumentedClient := infrastructure.NewInstrumentedClient(resilientClient, metrics)
+	presenceCacheClient := infrastructure.NewPresenceCacheClient(instrumentedClient, metrics)
+	localeCacheClient := infrastructure.NewLocaleCacheClient(presenceCacheClient, metrics)
 	reviewerMap := provideReviewerMap(slackConfig)
-	slackUsecaseImpl := usecase.NewSlackUsecase(client, reviewerMap)
-	controllerController := controller.NewController(slackUsecaseImpl)
-	server := rest.NewServer(controllerController)
-	mainApp := newApp(server)
-	return mainApp
+	codeHostLogins := provideCodeHostLogins(slackConfig)
+	reviewerStore := infrastructure.NewReviewerStore(kvStore, reviewerMap, codeHostLogins)
+	jobStore := infrastructure.NewJobStore(kvStore)
+	jobQueueConfig, err := config.NewJobQueueConfig()
+	if err != nil {
+		return nil, err
+	}
+	workerPoolOptions := provideWorkerPoolOptions(jobQueueConfig)
+	workerPool := infrastructure.NewWorkerPool(jobStore, workerPoolOptions)
+	idempotencyConfig, err := config.NewIdempotencyConfig()
+	if err != nil {
+		return nil, err
+	}
+	idempotencyRepository := provideIdempotencyRepository(kvStore, idempotencyConfig)
+	instrumentedReviewStore := infrastructure.NewInstrumentedReviewStore(reviewStore, metrics)
+	auditConfig, err := config.NewAuditConfig()
+	if err != nil {
+		return nil, err
+	}
+	auditRepository := provideAuditRepository(kvStore, auditConfig)
+	gitHubConfig, err := config.NewGitHubConfig()
+	if err != nil {
+		return nil, err
+	}
+	gitHubOptions := provideGitHubOptions(gitHubConfig)
+	gitHubClient := infrastructure.NewGitHubClient(gitHubOptions)
+	gitLabConfig, err := config.NewGitLabConfig()
+	if err != nil {
+		return nil, err
+	}
+	gitLabOptions := provideGitLabOptions(gitLabConfig)
+	gitLabClient := infrastructure.NewGitLabClient(gitLabOptions)
+	codeHosts := infrastructure.NewCodeHosts(gitHubClient, gitLabClient)
+	catalog, err := i18n.NewCatalog()
+	if err != nil {
+		return nil, err
+	}
+	languageConfig, err := config.NewLanguageConfig()
+	if err != nil {
+		return nil, err
+	}
+	slackUsecaseOptions := provideSlackUsecaseOptions(idempotencyConfig, languageConfig)
+	slackUsecaseImpl := usecase.NewSlackUsecase(localeCacheClient, reviewerStore, workerPool, idempotencyRepository, instrumentedReviewStore, auditRepository, codeHosts, catalog, slackUsecaseOptions)
+	healthConfig, err := config.NewHealthConfig()
+	if err != nil {
+		return nil, err
+	}
+	healthUsecaseOptions := provideHealthUsecaseOptions(slackConfig, healthConfig)
+	healthUsecaseImpl := usecase.NewHealthUsecase(localeCacheClient, reviewerStore, instrumentedReviewStore, healthUsecaseOptions)
+	auditUsecaseImpl := usecase.NewAuditUsecase(auditRepository)
+	statsUsecaseImpl := usecase.NewStatsUsecase(auditRepository)
+	exportUsecaseImpl := usecase.NewExportUsecase(instrumentedReviewStore, localeCacheClient)
+	dashboardConfig, err := config.NewDashboardConfig()
+	if err != nil {
+		return nil, err
+	}
+	slackSignInOptions := provideSlackSignInOptions(dashboardConfig)
+	slackSignInClient := infrastructure.NewSlackSignInClient(slackSignInOptions)
+	dashboardUsecaseOptions := provideDashboardUsecaseOptions(dashboardConfig, languageConfig)
+	dashboardUsecaseImpl := usecase.NewDashboardUsecase(instrumentedReviewStore, localeCacheClient, slackSignInClient, reviewerStore, catalog, dashboardUsecaseOptions)
+	reviewerUsecaseImpl := usecase.NewReviewerUsecase(reviewerStore, auditRepository)
+	sessionOptions := provideSessionOptions(dashboardConfig)
+	controllerController := controller.NewController(slackUsecaseImpl, healthUsecaseImpl, auditUsecaseImpl, statsUsecaseImpl, exportUsecaseImpl, dashboardUsecaseImpl, reviewerUsecaseImpl, slackUsecaseImpl, slackUsecaseImpl, sessionOptions)
+	metricsHandler := provideMetricsHandler(metrics)
+	serverConfig, err := config.NewServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	apiConfig, err := config.NewAPIConfig()
+	if err != nil {
+		return nil, err
+	}
+	serverOptions := provideServerOptions(serverConfig, apiConfig, dashboardConfig)
+	server := rest.NewServer(controllerController, metricsHandler, serverOptions)
+	tracingConfig := config.NewTracingConfig()
+	tracingOptions := provideTracingOptions(tracingConfig)
+	tracing, err := infrastructure.NewTracing(tracingOptions)
+	if err != nil {
+		return nil, err
+	}
+	mainApp := newApp(server, slackConfig, serverConfig, workerPool, kvStore, tracing, healthUsecaseImpl)
+	return mainApp, nil
 }
 
 // wire.go:
@@ -36,10 +133,132 @@ func provideOAuthToken(cfg *config.SlackConfig) model.OAuthToken {
 	return cfg.OAuthToken
 }
 
-func provideSigningSecret(cfg *config.SlackConfig) model.SigningSecret {
-	return cfg.SigningSecret
+func provideSigningSecretRepository(cfg *config.SlackConfig) repository.SigningSecretRepository {
+	return cfg.SigningSecrets
 }
 
 func provideReviewerMap(cfg *config.SlackConfig) model.ReviewerMap {
 	return cfg.ReviewerMap
 }
+
+func provideCodeHostLogins(cfg *config.SlackConfig) model.CodeHostLogins {
+	return cfg.Logins
+}
+
+func provideKVStore(cfg *config.StoreConfig) (infrastructure.KVStore, error) {
+	if cfg.Path == "" {
+		return infrastructure.NewMemoryKVStore(), nil
+	}
+	return infrastructure.NewBoltKVStore(cfg.Path)
+}
+
+func provideWorkerPoolOptions(cfg *config.JobQueueConfig) infrastructure.WorkerPoolOptions {
+	return infrastructure.WorkerPoolOptions{
+		Workers:     cfg.Workers,
+		QueueSize:   cfg.QueueSize,
+		JobTimeout:  cfg.JobTimeout,
+		MaxAttempts: cfg.MaxAttempts,
+		RetryDelay:  cfg.RetryDelay,
+	}
+}
+
+func provideIdempotencyRepository(kv infrastructure.KVStore, cfg *config.IdempotencyConfig) repository.IdempotencyRepository {
+	return infrastructure.NewIdempotencyStore(kv, cfg.TTL)
+}
+
+func provideAuditRepository(kv infrastructure.KVStore, cfg *config.AuditConfig) repository.AuditRepository {
+	return infrastructure.NewAuditStore(kv, cfg.Retention)
+}
+
+func provideSlackUsecaseOptions(cfg *config.IdempotencyConfig, languageCfg *config.LanguageConfig) usecase.SlackUsecaseOptions {
+	return usecase.SlackUsecaseOptions{
+		FastAck:          cfg.FastAck,
+		Language:         languageCfg.Default,
+		ChannelLanguages: languageCfg.Channels,
+		UserLocale:       languageCfg.UserLocale,
+	}
+}
+
+func provideMetricsHandler(metrics *infrastructure.Metrics) rest.MetricsHandler {
+	return metrics.Handler()
+}
+
+func provideTracingOptions(cfg *config.TracingConfig) infrastructure.TracingOptions {
+	return infrastructure.TracingOptions{
+		Enabled:     cfg.Enabled,
+		ServiceName: cfg.ServiceName,
+	}
+}
+
+func provideHealthUsecaseOptions(slackCfg *config.SlackConfig, healthCfg *config.HealthConfig) usecase.HealthUsecaseOptions {
+	return usecase.HealthUsecaseOptions{
+		ReviewerMapError:     slackCfg.ReviewerMapError,
+		AuthTestInterval:     healthCfg.AuthTestInterval,
+		AuthTestRetryBackoff: healthCfg.AuthTestRetryBackoff,
+		AuthTestMaxAge:       healthCfg.AuthTestMaxAge,
+	}
+}
+
+func provideSlackSignInOptions(cfg *config.DashboardConfig) infrastructure.SlackSignInOptions {
+	return infrastructure.SlackSignInOptions{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.BaseURL + "/dashboard/callback",
+		TeamID:       cfg.TeamID,
+	}
+}
+
+func provideDashboardUsecaseOptions(cfg *config.DashboardConfig, languageCfg *config.LanguageConfig) usecase.DashboardUsecaseOptions {
+	return usecase.DashboardUsecaseOptions{
+		TeamID:     cfg.TeamID,
+		Language:   languageCfg.Default,
+		UserLocale: languageCfg.UserLocale,
+	}
+}
+
+func provideSessionOptions(cfg *config.DashboardConfig) controller.SessionOptions {
+	return controller.SessionOptions{
+		Secret: []byte(cfg.SessionSecret),
+		TTL:    cfg.SessionTTL,
+		Secure: strings.HasPrefix(cfg.BaseURL, "https://"),
+		TeamID: cfg.TeamID,
+	}
+}
+
+func provideGitHubOptions(cfg *config.GitHubConfig) infrastructure.GitHubOptions {
+	return infrastructure.GitHubOptions{
+		Token:         cfg.Token,
+		APIURL:        cfg.APIURL,
+		WebhookSecret: cfg.WebhookSecret,
+	}
+}
+
+func provideGitLabOptions(cfg *config.GitLabConfig) infrastructure.GitLabOptions {
+	return infrastructure.GitLabOptions{
+		Token:        cfg.Token,
+		URL:          cfg.URL,
+		WebhookToken: cfg.WebhookToken,
+	}
+}
+
+func provideAPITokens(cfg *config.APIConfig) []rest.APIToken {
+	tokens := make([]rest.APIToken, len(cfg.Tokens))
+	for i, token := range cfg.Tokens {
+		tokens[i] = rest.APIToken{Name: token.Name, Token: token.Token, Scopes: token.Scopes}
+	}
+	return tokens
+}
+
+func provideServerOptions(cfg *config.ServerConfig, apiCfg *config.APIConfig, dashboardCfg *config.DashboardConfig) rest.ServerOptions {
+	return rest.ServerOptions{
+		Port:         cfg.Port,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		MaxBodyBytes: int64(cfg.MaxBodyBytes),
+		APITokens:    provideAPITokens(apiCfg),
+
+		AdminMemberIDs: apiCfg.AdminMemberIDs,
+
+		DashboardEnabled: dashboardCfg.Enabled(),
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// AllScopes is the scope of the keys in API_KEYS, which may do everything
+const AllScopes = "*"
+
+// APIToken is a bearer token of the HTTP API limited to some scopes
+type APIToken struct {
+	// Name tells the tokens apart in the logs
+	Name   string   `json:"name"`
+	Token  string   `json:"token"`
+	Scopes []string `json:"scopes"`
+}
+
+type APIConfig struct {
+	// Tokens are the bearer tokens accepted by the HTTP API; the API is disabled without any
+	Tokens []APIToken
+	// AdminMemberIDs are the members who may manage the reviewers through the API after signing in with Slack
+	AdminMemberIDs []string
+}
+
+func NewAPIConfig() (*APIConfig, error) {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	keys, err := resolveOptionalSecret(ctx, provider, APIKeysSecretName)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := resolveOptionalSecret(ctx, provider, APITokensSecretName)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &APIConfig{
+		AdminMemberIDs: splitList(os.Getenv("API_ADMIN_MEMBER_IDS")),
+	}
+	for i, key := range splitList(keys) {
+		cfg.Tokens = append(cfg.Tokens, APIToken{Name: fmt.Sprintf("api-key-%d", i+1), Token: key, Scopes: []string{AllScopes}})
+	}
+	if tokens != "" {
+		scoped, err := parseAPITokens(tokens)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Tokens = append(cfg.Tokens, scoped...)
+	}
+	if len(cfg.Tokens) == 0 {
+		slog.Info("no API keys configured, the HTTP API is disabled")
+	}
+	return cfg, nil
+}
+
+// parseAPITokens parses a JSON array of scoped tokens such as [{"name": "ci", "token": "...", "scopes": ["review_requests:write"]}]
+func parseAPITokens(value string) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := json.Unmarshal([]byte(value), &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", APITokensSecretName, err)
+	}
+	names := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		switch {
+		case token.Name == "" || token.Token == "":
+			return nil, fmt.Errorf("every token in %s needs a name and a token", APITokensSecretName)
+		case len(token.Scopes) == 0:
+			return nil, fmt.Errorf("token %s in %s has no scopes", token.Name, APITokensSecretName)
+		case names[token.Name]:
+			return nil, fmt.Errorf("token name %s is used twice in %s", token.Name, APITokensSecretName)
+		}
+		names[token.Name] = true
+	}
+	return tokens, nil
+}
+
+// splitList splits a comma-separated list, dropping empty elements
+func splitList(value string) []string {
+	var list []string
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type AuditConfig struct {
+	// Retention is how long audit events are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+func NewAuditConfig() (*AuditConfig, error) {
+	cfg := &AuditConfig{
+		Retention: 365 * 24 * time.Hour,
+	}
+	// 0 is not a positive duration, but keeps the audit log forever
+	if os.Getenv("AUDIT_RETENTION") == "0" {
+		cfg.Retention = 0
+	} else if err := lookupDuration("AUDIT_RETENTION", &cfg.Retention); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+type DashboardConfig struct {
+	// ClientID and ClientSecret are the credentials of the Slack app for Sign in with Slack;
+	// the dashboard is disabled without ClientID
+	ClientID     string
+	ClientSecret string
+	// TeamID is the workspace whose members may sign in
+	TeamID string
+	// BaseURL is the public URL of the server, such as https://review-bot.example.com, used for the redirect URL
+	BaseURL string
+	// SessionSecret signs the session cookies; sessions do not survive a restart without it
+	SessionSecret string
+	// SessionTTL is how long a sign-in lasts
+	SessionTTL time.Duration
+}
+
+func NewDashboardConfig() (*DashboardConfig, error) {
+	cfg := &DashboardConfig{
+		ClientID:   os.Getenv("SLACK_CLIENT_ID"),
+		TeamID:     os.Getenv("SLACK_TEAM_ID"),
+		BaseURL:    strings.TrimSuffix(os.Getenv("DASHBOARD_BASE_URL"), "/"),
+		SessionTTL: 12 * time.Hour,
+	}
+	if err := lookupDuration("DASHBOARD_SESSION_TTL", &cfg.SessionTTL); err != nil {
+		return nil, err
+	}
+	if cfg.ClientID == "" {
+		slog.Info("SLACK_CLIENT_ID is not set, the dashboard is disabled")
+		return cfg, nil
+	}
+	if cfg.TeamID == "" || cfg.BaseURL == "" {
+		return nil, errors.New("SLACK_TEAM_ID and DASHBOARD_BASE_URL must be set for the dashboard")
+	}
+
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if cfg.ClientSecret, err = resolveSecret(ctx, provider, ClientSecretSecretName, ""); err != nil {
+		return nil, err
+	}
+	if cfg.SessionSecret, err = resolveOptionalSecret(ctx, provider, SessionSecretSecretName); err != nil {
+		return nil, err
+	}
+	if cfg.SessionSecret == "" {
+		slog.Warn("DASHBOARD_SESSION_SECRET is not set, dashboard sessions end on restart and are not shared between instances")
+	}
+	return cfg, nil
+}
+
+// Enabled reports whether the dashboard is served
+func (c *DashboardConfig) Enabled() bool {
+	return c.ClientID != ""
+}
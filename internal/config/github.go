@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+type GitHubConfig struct {
+	// Token authenticates the GitHub API; pull requests are not looked up without it
+	Token string
+	// APIURL is the base URL of the GitHub REST API, https://api.github.com unless set
+	APIURL string
+	// WebhookSecret verifies the webhook deliveries of GitHub; webhooks are rejected without it
+	WebhookSecret string
+}
+
+func NewGitHubConfig() (*GitHubConfig, error) {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cfg := &GitHubConfig{
+		APIURL: os.Getenv("GITHUB_API_URL"),
+	}
+	if cfg.Token, err = resolveOptionalSecret(ctx, provider, GitHubTokenSecretName); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookSecret, err = resolveOptionalSecret(ctx, provider, GitHubWebhookSecretSecretName); err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		slog.Info("GITHUB_TOKEN is not set, pull requests are not looked up on GitHub")
+	}
+	return cfg, nil
+}
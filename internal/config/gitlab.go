@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+type GitLabConfig struct {
+	// Token authenticates the GitLab API; merge requests are not looked up without it
+	Token string
+	// URL is where the GitLab instance is served, https://gitlab.com unless set
+	URL string
+	// WebhookToken verifies the webhook deliveries of GitLab; webhooks are rejected without it
+	WebhookToken string
+}
+
+func NewGitLabConfig() (*GitLabConfig, error) {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cfg := &GitLabConfig{
+		URL: os.Getenv("GITLAB_URL"),
+	}
+	if cfg.Token, err = resolveOptionalSecret(ctx, provider, GitLabTokenSecretName); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookToken, err = resolveOptionalSecret(ctx, provider, GitLabWebhookTokenSecretName); err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		slog.Info("GITLAB_TOKEN is not set, merge requests are not looked up on GitLab")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+)
+
+type HealthConfig struct {
+	// AuthTestInterval is how often auth.test is called to check the OAuth token
+	AuthTestInterval time.Duration
+	// AuthTestRetryBackoff is the first delay before auth.test is retried while it has never succeeded
+	AuthTestRetryBackoff time.Duration
+	// AuthTestMaxAge is how old the last successful auth.test may be for the server to be ready
+	AuthTestMaxAge time.Duration
+}
+
+func NewHealthConfig() (*HealthConfig, error) {
+	cfg := &HealthConfig{
+		AuthTestInterval:     time.Minute,
+		AuthTestRetryBackoff: time.Second,
+		AuthTestMaxAge:       5 * time.Minute,
+	}
+	if err := lookupDuration("HEALTH_AUTH_TEST_INTERVAL", &cfg.AuthTestInterval); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("HEALTH_AUTH_TEST_RETRY_BACKOFF", &cfg.AuthTestRetryBackoff); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("HEALTH_AUTH_TEST_MAX_AGE", &cfg.AuthTestMaxAge); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+)
+
+type IdempotencyConfig struct {
+	// TTL is how long handled event IDs are remembered; Slack retries within minutes
+	TTL time.Duration
+	// FastAck acknowledges app mentions immediately and handles them in the background, which it does by default
+	FastAck bool
+}
+
+func NewIdempotencyConfig() (*IdempotencyConfig, error) {
+	cfg := &IdempotencyConfig{
+		TTL:     time.Hour,
+		FastAck: true,
+	}
+	if err := lookupBool("SLACK_FAST_ACK", &cfg.FastAck); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("IDEMPOTENCY_TTL", &cfg.TTL); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
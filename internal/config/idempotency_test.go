@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestNewIdempotencyConfigFastAck(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{value: "", want: true},
+		{value: "true", want: true},
+		{value: "false", want: false},
+		{value: "0", want: false},
+		{value: "no", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("SLACK_FAST_ACK", tt.value)
+		cfg, err := NewIdempotencyConfig()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewIdempotencyConfig() with SLACK_FAST_ACK=%q error = %v, want error %v", tt.value, err, tt.wantErr)
+		}
+		if err == nil && cfg.FastAck != tt.want {
+			t.Errorf("FastAck with SLACK_FAST_ACK=%q = %v, want %v", tt.value, cfg.FastAck, tt.want)
+		}
+	}
+}
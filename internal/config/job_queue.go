@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type JobQueueConfig struct {
+	Workers     int
+	QueueSize   int
+	JobTimeout  time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+func NewJobQueueConfig() (*JobQueueConfig, error) {
+	cfg := &JobQueueConfig{
+		Workers:     4,
+		QueueSize:   100,
+		JobTimeout:  30 * time.Second,
+		MaxAttempts: 3,
+		RetryDelay:  2 * time.Second,
+	}
+	if err := lookupInt("JOB_WORKERS", &cfg.Workers); err != nil {
+		return nil, err
+	}
+	if err := lookupInt("JOB_QUEUE_SIZE", &cfg.QueueSize); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("JOB_TIMEOUT", &cfg.JobTimeout); err != nil {
+		return nil, err
+	}
+	if err := lookupInt("JOB_MAX_ATTEMPTS", &cfg.MaxAttempts); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("JOB_RETRY_DELAY", &cfg.RetryDelay); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// lookupInt overrides dst with the positive integer in the environment variable, if set
+func lookupInt(name string, dst *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid %s: must be a positive integer", name)
+	}
+	*dst = n
+	return nil
+}
+
+// lookupBool overrides dst with the boolean in the environment variable, if set
+func lookupBool(name string, dst *bool) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: must be true or false", name)
+	}
+	*dst = b
+	return nil
+}
+
+// lookupDuration overrides dst with the positive duration in the environment variable, if set
+func lookupDuration(name string, dst *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid %s: must be a positive duration", name)
+	}
+	*dst = d
+	return nil
+}
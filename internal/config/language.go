@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+type LanguageConfig struct {
+	// Default is the language of the messages in the workspace, Japanese unless set
+	Default i18n.Language
+	// Channels are the languages of the channels whose messages are written in another language
+	Channels map[string]i18n.Language
+	// UserLocale writes the messages addressed to a member in the language of their Slack locale
+	UserLocale bool
+}
+
+func NewLanguageConfig() (*LanguageConfig, error) {
+	cfg := &LanguageConfig{
+		Default:    i18n.Japanese,
+		Channels:   map[string]i18n.Language{},
+		UserLocale: os.Getenv("SLACK_USER_LOCALE") == "true",
+	}
+	if value := os.Getenv("SLACK_LANGUAGE"); value != "" {
+		language, ok := i18n.ParseLanguage(value)
+		if !ok {
+			return nil, fmt.Errorf("unsupported language %q in SLACK_LANGUAGE", value)
+		}
+		cfg.Default = language
+	}
+	// e.g. SLACK_CHANNEL_LANGUAGES=C0123456=en,C0456789=ja
+	for _, element := range splitList(os.Getenv("SLACK_CHANNEL_LANGUAGES")) {
+		channelID, value, ok := strings.Cut(element, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed element %q in SLACK_CHANNEL_LANGUAGES, expected channel=language", element)
+		}
+		language, ok := i18n.ParseLanguage(value)
+		if !ok {
+			return nil, fmt.Errorf("unsupported language %q for channel %s in SLACK_CHANNEL_LANGUAGES", value, channelID)
+		}
+		cfg.Channels[strings.TrimSpace(channelID)] = language
+	}
+	return cfg, nil
+}
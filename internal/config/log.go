@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+type LogConfig struct {
+	// Level is the minimum level of the records written
+	Level slog.Level
+}
+
+func NewLogConfig() (*LogConfig, error) {
+	cfg := &LogConfig{
+		Level: slog.LevelInfo,
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if err := cfg.Level.UnmarshalText([]byte(v)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: must be one of debug, info, warn or error")
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OnePasswordConnectProvider resolves secrets from the fields of a 1Password item through a 1Password Connect server
+type OnePasswordConnectProvider struct {
+	host       string
+	token      string
+	vaultName  string
+	itemName   string
+	labels     map[string]string
+	httpClient *http.Client
+}
+
+var _ SecretProvider = (*OnePasswordConnectProvider)(nil)
+
+// NewOnePasswordConnectProvider creates a provider reading the item itemName in the vault vaultName.
+// labels maps secret names to the labels of the item fields holding them; unmapped names are used as labels verbatim.
+func NewOnePasswordConnectProvider(host, token, vaultName, itemName string, labels map[string]string) *OnePasswordConnectProvider {
+	if vaultName == "" {
+		vaultName = "Slack Review Request Bot"
+	}
+	if itemName == "" {
+		itemName = "Secrets"
+	}
+	return &OnePasswordConnectProvider{
+		host:      strings.TrimRight(host, "/"),
+		token:     token,
+		vaultName: vaultName,
+		itemName:  itemName,
+		labels:    labels,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type onePasswordVault struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type onePasswordItem struct {
+	ID     string             `json:"id"`
+	Title  string             `json:"title"`
+	Fields []onePasswordField `json:"fields"`
+}
+
+type onePasswordField struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+func (p *OnePasswordConnectProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	label, ok := p.labels[name]
+	if !ok {
+		label = name
+	}
+	// Resolve the vault and item IDs from their names
+	var vaults []onePasswordVault
+	if err := p.get(ctx, "/v1/vaults?filter="+url.QueryEscape(`name eq "`+p.vaultName+`"`), &vaults); err != nil {
+		return "", err
+	}
+	if len(vaults) == 0 {
+		return "", fmt.Errorf("%w: 1Password vault %q", ErrSecretNotFound, p.vaultName)
+	}
+	vaultID := vaults[0].ID
+	var items []onePasswordItem
+	if err := p.get(ctx, "/v1/vaults/"+vaultID+"/items?filter="+url.QueryEscape(`title eq "`+p.itemName+`"`), &items); err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("%w: 1Password item %q", ErrSecretNotFound, p.itemName)
+	}
+	// The item list does not include field values, so fetch the full item
+	var item onePasswordItem
+	if err := p.get(ctx, "/v1/vaults/"+vaultID+"/items/"+items[0].ID, &item); err != nil {
+		return "", err
+	}
+	for _, f := range item.Fields {
+		if f.Label == label && f.Value != "" {
+			return f.Value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: 1Password field %q", ErrSecretNotFound, label)
+}
+
+func (p *OnePasswordConnectProvider) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.host+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("1Password Connect returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
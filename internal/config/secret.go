@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// OAuthTokenSecretName is the name under which the Slack OAuth token is resolved
+	OAuthTokenSecretName = "SLACK_OAUTH_TOKEN"
+	// SigningSecretSecretName is the name under which the Slack signing secret is resolved
+	SigningSecretSecretName = "SLACK_SIGNING_SECRET"
+	// APIKeysSecretName is the name under which the comma-separated keys of the HTTP API are resolved
+	APIKeysSecretName = "API_KEYS"
+	// APITokensSecretName is the name under which the scoped tokens of the HTTP API are resolved as a JSON array
+	APITokensSecretName = "API_TOKENS"
+	// ClientSecretSecretName is the name under which the Slack app's client secret for Sign in with Slack is resolved
+	ClientSecretSecretName = "SLACK_CLIENT_SECRET"
+	// SessionSecretSecretName is the name under which the key signing dashboard sessions is resolved
+	SessionSecretSecretName = "DASHBOARD_SESSION_SECRET"
+	// GitHubTokenSecretName is the name under which the token of the GitHub API is resolved
+	GitHubTokenSecretName = "GITHUB_TOKEN"
+	// GitHubWebhookSecretSecretName is the name under which the secret signing GitHub's webhook deliveries is resolved
+	GitHubWebhookSecretSecretName = "GITHUB_WEBHOOK_SECRET"
+	// GitLabTokenSecretName is the name under which the token of the GitLab API is resolved
+	GitLabTokenSecretName = "GITLAB_TOKEN"
+	// GitLabWebhookTokenSecretName is the name under which the secret token of GitLab's webhooks is resolved
+	GitLabWebhookTokenSecretName = "GITLAB_WEBHOOK_TOKEN"
+)
+
+// ErrSecretNotFound is returned when a provider has no value for the requested secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves secrets by name at runtime
+type SecretProvider interface {
+	// GetSecret returns the value of the named secret, or ErrSecretNotFound
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecretProvider resolves secrets from environment variables
+type EnvSecretProvider struct{}
+
+var _ SecretProvider = (*EnvSecretProvider)(nil)
+
+func NewEnvSecretProvider() *EnvSecretProvider {
+	return &EnvSecretProvider{}
+}
+
+func (p *EnvSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: environment variable %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// FileSecretProvider resolves secrets from files in a directory, one file per secret.
+// This matches how Cloud Run and Kubernetes mount secrets as volumes.
+type FileSecretProvider struct {
+	dir string
+}
+
+var _ SecretProvider = (*FileSecretProvider)(nil)
+
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{
+		dir: dir,
+	}
+}
+
+func (p *FileSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	path := filepath.Join(p.dir, name)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: file %s", ErrSecretNotFound, path)
+		}
+		return "", err
+	}
+	// Mounted secrets commonly end with a trailing newline
+	value := strings.TrimSpace(string(b))
+	if value == "" {
+		return "", fmt.Errorf("%w: file %s is empty", ErrSecretNotFound, path)
+	}
+	return value, nil
+}
+
+// NewSecretProvider creates the secret provider selected by the SECRET_PROVIDER environment variable.
+// Supported values are "env" (default), "file" and "1password".
+func NewSecretProvider() (SecretProvider, error) {
+	switch kind := os.Getenv("SECRET_PROVIDER"); kind {
+	case "", "env":
+		return NewEnvSecretProvider(), nil
+	case "file":
+		dir := os.Getenv("SECRET_DIR")
+		if dir == "" {
+			dir = "/secrets"
+		}
+		return NewFileSecretProvider(dir), nil
+	case "1password":
+		host := os.Getenv("OP_CONNECT_HOST")
+		token := os.Getenv("OP_CONNECT_TOKEN")
+		if host == "" || token == "" {
+			return nil, errors.New("OP_CONNECT_HOST and OP_CONNECT_TOKEN must be set for the 1password secret provider")
+		}
+		return NewOnePasswordConnectProvider(
+			host,
+			token,
+			os.Getenv("OP_VAULT_NAME"),
+			os.Getenv("OP_ITEM_NAME"),
+			map[string]string{
+				OAuthTokenSecretName:    "Slack OAuth Token",
+				SigningSecretSecretName: "Slack Signing Secret",
+			},
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported secret provider: %s", kind)
+	}
+}
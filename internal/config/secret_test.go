@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvSecretProvider(t *testing.T) {
+	t.Setenv("TEST_SECRET", "value")
+	t.Setenv("TEST_EMPTY_SECRET", "")
+	provider := NewEnvSecretProvider()
+
+	value, err := provider.GetSecret(context.Background(), "TEST_SECRET")
+	if err != nil || value != "value" {
+		t.Fatalf("GetSecret(TEST_SECRET) = %q, %v, want value", value, err)
+	}
+	for _, name := range []string{"TEST_EMPTY_SECRET", "TEST_MISSING_SECRET"} {
+		if _, err := provider.GetSecret(context.Background(), name); !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("GetSecret(%s) error = %v, want ErrSecretNotFound", name, err)
+		}
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"SECRET":       "value\n",
+		"EMPTY_SECRET": " \n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := NewFileSecretProvider(dir)
+
+	value, err := provider.GetSecret(context.Background(), "SECRET")
+	if err != nil || value != "value" {
+		t.Fatalf("GetSecret(SECRET) = %q, %v, want value without the trailing newline", value, err)
+	}
+	for _, name := range []string{"EMPTY_SECRET", "MISSING_SECRET"} {
+		if _, err := provider.GetSecret(context.Background(), name); !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("GetSecret(%s) error = %v, want ErrSecretNotFound", name, err)
+		}
+	}
+}
+
+// fakeOnePasswordConnect serves a vault with one item the way 1Password Connect does
+func fakeOnePasswordConnect(t *testing.T, fields []onePasswordField) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer connect-token" {
+			http.Error(w, `{"status":401,"message":"Invalid token"}`, http.StatusUnauthorized)
+			return
+		}
+		filter := r.URL.Query().Get("filter")
+		var v any
+		switch r.URL.Path {
+		case "/v1/vaults":
+			vaults := []onePasswordVault{}
+			if filter == `name eq "Bot"` {
+				vaults = append(vaults, onePasswordVault{ID: "vault-1", Name: "Bot"})
+			}
+			v = vaults
+		case "/v1/vaults/vault-1/items":
+			items := []onePasswordItem{}
+			if filter == `title eq "Secrets"` {
+				items = append(items, onePasswordItem{ID: "item-1", Title: "Secrets"})
+			}
+			v = items
+		case "/v1/vaults/vault-1/items/item-1":
+			v = onePasswordItem{ID: "item-1", Title: "Secrets", Fields: fields}
+		default:
+			http.Error(w, `{"status":404,"message":"Not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOnePasswordConnectProvider(t *testing.T) {
+	server := fakeOnePasswordConnect(t, []onePasswordField{
+		{ID: "1", Label: "Slack OAuth Token", Value: "xoxb-token"},
+		{ID: "2", Label: "API_KEYS", Value: "key"},
+		{ID: "3", Label: "EMPTY", Value: ""},
+	})
+	labels := map[string]string{OAuthTokenSecretName: "Slack OAuth Token"}
+
+	tests := []struct {
+		name      string
+		token     string
+		vaultName string
+		itemName  string
+		secret    string
+		want      string
+		wantErr   error
+		errSubstr string
+	}{
+		{name: "mapped label", token: "connect-token", vaultName: "Bot", secret: OAuthTokenSecretName, want: "xoxb-token"},
+		{name: "unmapped name as label", token: "connect-token", vaultName: "Bot", secret: "API_KEYS", want: "key"},
+		{name: "missing field", token: "connect-token", vaultName: "Bot", secret: "MISSING", wantErr: ErrSecretNotFound},
+		{name: "empty field", token: "connect-token", vaultName: "Bot", secret: "EMPTY", wantErr: ErrSecretNotFound},
+		{name: "missing vault", token: "connect-token", vaultName: "Other", secret: "API_KEYS", wantErr: ErrSecretNotFound},
+		{name: "missing item", token: "connect-token", vaultName: "Bot", itemName: "Other", secret: "API_KEYS", wantErr: ErrSecretNotFound},
+		{name: "rejected token", token: "wrong-token", vaultName: "Bot", secret: "API_KEYS", errSubstr: "returned 401"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A trailing slash on the host must not double the slash of the paths
+			provider := NewOnePasswordConnectProvider(server.URL+"/", tt.token, tt.vaultName, tt.itemName, labels)
+			got, err := provider.GetSecret(context.Background(), tt.secret)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetSecret() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errSubstr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Fatalf("GetSecret() error = %v, want one containing %q", err, tt.errSubstr)
+				}
+			case err != nil:
+				t.Fatalf("GetSecret() error = %v", err)
+			case got != tt.want:
+				t.Fatalf("GetSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type ServerConfig struct {
+	// Port is the port the server listens on; Cloud Run sets PORT
+	Port string
+	// ReadTimeout bounds reading a request including its body
+	ReadTimeout time.Duration
+	// WriteTimeout bounds handling a request and writing its response
+	WriteTimeout time.Duration
+	// MaxBodyBytes is the largest request body accepted; Slack payloads are a few kilobytes
+	MaxBodyBytes int
+	// ShutdownTimeout bounds draining in-flight requests and background jobs after SIGTERM.
+	// Cloud Run kills the instance 10 seconds after sending SIGTERM.
+	ShutdownTimeout time.Duration
+}
+
+func NewServerConfig() (*ServerConfig, error) {
+	cfg := &ServerConfig{
+		Port:            os.Getenv("PORT"),
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		MaxBodyBytes:    1 << 20,
+		ShutdownTimeout: 9 * time.Second,
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	if err := lookupDuration("READ_TIMEOUT", &cfg.ReadTimeout); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("WRITE_TIMEOUT", &cfg.WriteTimeout); err != nil {
+		return nil, err
+	}
+	if err := lookupInt("MAX_BODY_BYTES", &cfg.MaxBodyBytes); err != nil {
+		return nil, err
+	}
+	if err := lookupDuration("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// PreviousSigningSecretsSecretName is the name under which the previous signing secrets are resolved.
+// The value is a JSON array of objects with "secret" and "expires_at" (RFC 3339) keys.
+const PreviousSigningSecretsSecretName = "SLACK_PREVIOUS_SIGNING_SECRETS"
+
+// SigningSecretStore holds the signing secrets accepted for request verification and can reload them at runtime
+type SigningSecretStore struct {
+	provider SecretProvider
+	secrets  atomic.Pointer[model.SigningSecrets]
+}
+
+var _ repository.SigningSecretRepository = (*SigningSecretStore)(nil)
+
+// NewSigningSecretStore creates a store and loads the initial signing secrets from the provider
+func NewSigningSecretStore(ctx context.Context, provider SecretProvider) (*SigningSecretStore, error) {
+	s := &SigningSecretStore{
+		provider: provider,
+	}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SigningSecretStore) GetSigningSecrets() model.SigningSecrets {
+	return *s.secrets.Load()
+}
+
+// Reload resolves the signing secrets from the provider again and replaces the current set.
+// The current set is kept when resolution fails.
+func (s *SigningSecretStore) Reload(ctx context.Context) error {
+	primary, err := resolveSecret(ctx, s.provider, SigningSecretSecretName, SigningSecret)
+	if err != nil {
+		return err
+	}
+	var previous []model.PreviousSigningSecret
+	raw, err := s.provider.GetSecret(ctx, PreviousSigningSecretsSecretName)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal([]byte(raw), &previous); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", PreviousSigningSecretsSecretName, err)
+		}
+	case errors.Is(err, ErrSecretNotFound):
+		// Previous secrets are only present while a rotation is in progress
+	default:
+		return fmt.Errorf("failed to resolve secret %s: %w", PreviousSigningSecretsSecretName, err)
+	}
+	s.secrets.Store(&model.SigningSecrets{
+		Primary:  model.SigningSecret(primary),
+		Previous: previous,
+	})
+	slog.Info("signing secrets loaded", "previous_count", len(previous))
+	return nil
+}
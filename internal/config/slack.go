@@ -1,40 +1,142 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
 )
 
+// Deprecated: OAuthToken and SigningSecret are set with -ldflags -X at build time, which bakes the credentials
+// into the binary. They are only used as a fallback when the secret provider has no value.
 var (
 	OAuthToken    = ""
 	SigningSecret = ""
 )
 
 type SlackConfig struct {
-	OAuthToken    model.OAuthToken
-	SigningSecret model.SigningSecret
-	ReviewerMap   model.ReviewerMap
+	OAuthToken     model.OAuthToken
+	SigningSecrets *SigningSecretStore
+	ReviewerMap    model.ReviewerMap
+	// Logins are the logins of the reviewers on the code hosts from the optional github_logins.json and gitlab_usernames.json
+	Logins model.CodeHostLogins
+	// ReviewerMapError is why one of the reviewer configuration files could not be loaded; the server then never becomes ready
+	ReviewerMapError error
+	// SigningSecretReloadInterval is how often the signing secrets are reloaded, or zero to reload only on SIGHUP
+	SigningSecretReloadInterval time.Duration
 }
 
-func NewSlackConfig() *SlackConfig {
-	token := OAuthToken
-	secret := SigningSecret
-	reviewerMap := make(model.ReviewerMap)
+func NewSlackConfig() (*SlackConfig, error) {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	reviewerMapBytes, err := os.ReadFile("reviewer_map.json")
+	token, err := resolveSecret(ctx, provider, OAuthTokenSecretName, OAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	signingSecrets, err := NewSigningSecretStore(ctx, provider)
 	if err != nil {
-		slog.Error("failed to read reviewer map file", "error", err)
+		return nil, err
+	}
+	var reloadInterval time.Duration
+	if err := lookupDuration("SIGNING_SECRET_RELOAD_INTERVAL", &reloadInterval); err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(reviewerMapBytes, &reviewerMap); err != nil {
-		slog.Error("failed to parse reviewer map config", "error", err)
+	reviewerMap, reviewerMapErr := loadReviewerMap("reviewer_map.json")
+	if reviewerMapErr != nil {
+		slog.Error("failed to load reviewer map", "error", reviewerMapErr)
+	}
+	logins := model.CodeHostLogins{}
+	for host, path := range map[model.CodeHost]string{
+		model.CodeHostGitHub: "github_logins.json",
+		model.CodeHostGitLab: "gitlab_usernames.json",
+	} {
+		var err error
+		if logins[host], err = loadLogins(path, host, reviewerMap); err != nil {
+			slog.Error("failed to load logins", "host", host, "error", err)
+			reviewerMapErr = errors.Join(reviewerMapErr, err)
+		}
 	}
 
 	return &SlackConfig{
-		OAuthToken:    model.OAuthToken(token),
-		SigningSecret: model.SigningSecret(secret),
-		ReviewerMap:   reviewerMap,
+		OAuthToken:                  model.OAuthToken(token),
+		SigningSecrets:              signingSecrets,
+		ReviewerMap:                 reviewerMap,
+		Logins:                      logins,
+		ReviewerMapError:            reviewerMapErr,
+		SigningSecretReloadInterval: reloadInterval,
+	}, nil
+}
+
+// loadReviewerMap reads the reviewer map from path, returning an empty map along with the error if it cannot
+func loadReviewerMap(path string) (model.ReviewerMap, error) {
+	reviewerMap := make(model.ReviewerMap)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return reviewerMap, fmt.Errorf("failed to read reviewer map file: %w", err)
+	}
+	if err := json.Unmarshal(b, &reviewerMap); err != nil {
+		return make(model.ReviewerMap), fmt.Errorf("failed to parse reviewer map config: %w", err)
+	}
+	if err := reviewerMap.Validate(); err != nil {
+		return make(model.ReviewerMap), fmt.Errorf("failed to validate reviewer map config: %w", err)
+	}
+	return reviewerMap, nil
+}
+
+// loadLogins reads the logins of the reviewers on the code host from path, a JSON object from member IDs to logins.
+// The file is optional; without it, no reviewer has a login until one is set through the reviewer API.
+func loadLogins(path string, host model.CodeHost, reviewerMap model.ReviewerMap) (model.Logins, error) {
+	logins := make(model.Logins)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return logins, nil
+	}
+	if err != nil {
+		return logins, fmt.Errorf("failed to read %s logins file: %w", host.Name(), err)
+	}
+	if err := json.Unmarshal(b, &logins); err != nil {
+		return make(model.Logins), fmt.Errorf("failed to parse %s logins config: %w", host.Name(), err)
+	}
+	if err := logins.Validate(host, reviewerMap); err != nil {
+		return make(model.Logins), fmt.Errorf("failed to validate %s logins config: %w", host.Name(), err)
+	}
+	return logins, nil
+}
+
+// resolveSecret resolves the named secret from the provider, falling back to the value baked in with ldflags
+func resolveSecret(ctx context.Context, provider SecretProvider, name, fallback string) (string, error) {
+	value, err := provider.GetSecret(ctx, name)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrSecretNotFound) {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", name, err)
+	}
+	if fallback == "" {
+		return "", err
+	}
+	slog.Warn("using secret baked in at build time, which is deprecated", "name", name)
+	return fallback, nil
+}
+
+// resolveOptionalSecret returns the value of the secret, or an empty string if the provider has none
+func resolveOptionalSecret(ctx context.Context, provider SecretProvider, name string) (string, error) {
+	value, err := provider.GetSecret(ctx, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", name, err)
 	}
+	return value, nil
 }
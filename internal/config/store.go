@@ -0,0 +1,27 @@
+package config
+
+import (
+	"errors"
+	"os"
+)
+
+type StoreConfig struct {
+	// Path is the bbolt database file; when empty, everything is kept in memory and lost on restart
+	Path string
+	// InMemory allows running without Path on Cloud Run
+	InMemory bool
+}
+
+func NewStoreConfig() (*StoreConfig, error) {
+	cfg := &StoreConfig{
+		Path: os.Getenv("STORE_PATH"),
+	}
+	if err := lookupBool("STORE_IN_MEMORY", &cfg.InMemory); err != nil {
+		return nil, err
+	}
+	// Cloud Run sets K_SERVICE. It stops idle instances, which would lose the reviews, the roster and the audit log.
+	if cfg.Path == "" && !cfg.InMemory && os.Getenv("K_SERVICE") != "" {
+		return nil, errors.New("STORE_PATH must be set on Cloud Run, where everything kept in memory is lost when the instance stops; set STORE_IN_MEMORY=true to run without persistence anyway")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestNewStoreConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		service  string
+		inMemory string
+		wantErr  bool
+	}{
+		{name: "in memory outside of Cloud Run"},
+		{name: "file on Cloud Run", path: "/mnt/store/bot.db", service: "bot"},
+		{name: "in memory on Cloud Run", service: "bot", wantErr: true},
+		{name: "in memory on Cloud Run on purpose", service: "bot", inMemory: "true"},
+		{name: "malformed STORE_IN_MEMORY", inMemory: "yes please", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORE_PATH", tt.path)
+			t.Setenv("K_SERVICE", tt.service)
+			t.Setenv("STORE_IN_MEMORY", tt.inMemory)
+			cfg, err := NewStoreConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStoreConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Path != tt.path {
+				t.Errorf("Path = %q, want %q", cfg.Path, tt.path)
+			}
+		})
+	}
+}
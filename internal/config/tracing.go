@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+)
+
+type TracingConfig struct {
+	// Enabled is set when an OTLP endpoint is configured, e.g. OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318
+	Enabled bool
+	// ServiceName is the default service.name of the exported spans
+	ServiceName string
+}
+
+func NewTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		Enabled:     os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "",
+		ServiceName: "slack-review-request-bot",
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// AuditEventType represents what happened to a review request
+type AuditEventType string
+
+const (
+	// AuditEventSelectionPosted means the reviewer selection message was posted or restored
+	AuditEventSelectionPosted AuditEventType = "selection_posted"
+	// AuditEventRequested means a review was requested through the API, without a selection message
+	AuditEventRequested AuditEventType = "requested"
+	// AuditEventClicked means someone clicked a button or picked a reviewer on a message of the bot
+	AuditEventClicked AuditEventType = "clicked"
+	// AuditEventAssigned means a reviewer was assigned
+	AuditEventAssigned AuditEventType = "assigned"
+	// AuditEventReassigned means the assigned reviewer was replaced
+	AuditEventReassigned AuditEventType = "reassigned"
+	// AuditEventCompleted means the reviewer marked the review as done
+	AuditEventCompleted AuditEventType = "completed"
+	// AuditEventPullRequestUpdated means the pull request under review was approved, merged or closed,
+	// or changes were requested on it, with the action as Detail
+	AuditEventPullRequestUpdated AuditEventType = "pull_request_updated"
+	// AuditEventAdminChanged means an administrator changed the configuration of the bot
+	AuditEventAdminChanged AuditEventType = "admin_changed"
+)
+
+// AuditEvent is an entry of the append-only audit log
+type AuditEvent struct {
+	ID        string         `json:"id"`
+	Type      AuditEventType `json:"type"`
+	ReviewID  string         `json:"review_id,omitempty"`
+	ChannelID string         `json:"channel_id,omitempty"`
+	ThreadTS  string         `json:"thread_ts,omitempty"`
+	// ActorID is who caused the event; empty for the bot itself
+	ActorID MemberID `json:"actor_id,omitempty"`
+	// ExternalActor is who caused the event outside Slack, such as a GitHub login
+	ExternalActor string `json:"external_actor,omitempty"`
+	// ExternalHost is the code host of the external actor; GitHub if empty
+	ExternalHost CodeHost       `json:"external_host,omitempty"`
+	Mode         AssignmentMode `json:"mode,omitempty"`
+	// Candidates are the reviewers the assignment chose from, and Excluded the members left out of them
+	Candidates       []MemberID `json:"candidates,omitempty"`
+	Excluded         []MemberID `json:"excluded,omitempty"`
+	Reviewer         *Member    `json:"reviewer,omitempty"`
+	PreviousReviewer *Member    `json:"previous_reviewer,omitempty"`
+	// Detail is a free-form note, e.g. the outcome of a click or what an administrator changed
+	Detail string    `json:"detail,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// NewAuditEvent creates an audit event about the review in the given thread
+func NewAuditEvent(eventType AuditEventType, channelID, threadTS string, actorID MemberID, now time.Time) *AuditEvent {
+	event := &AuditEvent{
+		Type:      eventType,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		ActorID:   actorID,
+		At:        now,
+	}
+	if channelID != "" && threadTS != "" {
+		event.ReviewID = ReviewID(channelID, threadTS)
+	}
+	return event
+}
+
+// auditThreadClockSkew is how much earlier than the message of its thread an event of a review may be recorded,
+// because the time of the message comes from Slack and the time of the event from the bot
+const auditThreadClockSkew = time.Minute
+
+// AuditQuery selects audit events; zero fields do not restrict the result
+type AuditQuery struct {
+	ReviewID string
+	// From is the time the events are read since; the log is ordered by time, so older events are not read at all
+	From time.Time
+	To   time.Time
+	// Limit is the most events returned, the oldest ones
+	Limit int
+}
+
+// ReviewAuditQuery selects the audit events of the review in the thread.
+// They all happen after the thread was started, so the log is read from the time of its message on.
+func ReviewAuditQuery(channelID, threadTS string) AuditQuery {
+	query := AuditQuery{ReviewID: ReviewID(channelID, threadTS)}
+	if seconds, err := strconv.ParseFloat(threadTS, 64); err == nil {
+		query.From = time.Unix(int64(seconds), 0).Add(-auditThreadClockSkew)
+	}
+	return query
+}
+
+// Matches reports whether the event is selected by the query
+func (q AuditQuery) Matches(event *AuditEvent) bool {
+	if q.ReviewID != "" && event.ReviewID != q.ReviewID {
+		return false
+	}
+	if !q.From.IsZero() && event.At.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !event.At.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// MemberIDs returns the member IDs of the members
+func MemberIDs(members []Member) []MemberID {
+	memberIDs := make([]MemberID, len(members))
+	for i, member := range members {
+		memberIDs[i] = member.MemberID
+	}
+	return memberIDs
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CodeHost is where pull requests are reviewed, such as GitHub or GitLab
+type CodeHost string
+
+const (
+	CodeHostGitHub CodeHost = "github"
+	CodeHostGitLab CodeHost = "gitlab"
+)
+
+// AllCodeHosts are the code hosts the bot supports
+var AllCodeHosts = []CodeHost{CodeHostGitHub, CodeHostGitLab}
+
+// Name returns the name of the code host as its users know it
+func (h CodeHost) Name() string {
+	switch h {
+	case CodeHostGitLab:
+		return "GitLab"
+	default:
+		return "GitHub"
+	}
+}
+
+// PullRequestRef identifies a pull request on GitHub or a merge request on GitLab
+type PullRequestRef struct {
+	Host CodeHost `json:"host"`
+	// BaseURL is where the GitLab instance of a merge request is served
+	BaseURL string `json:"base_url,omitempty"`
+	// Owner is the owner of the repository on GitHub, or the namespace of the project on GitLab,
+	// which may have several levels
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+// findPullRequestRefs returns the pull requests linked to in the text in the order they appear, each once.
+// The pattern matches the owner, the repository and the number of a pull request of the code host of the base.
+func findPullRequestRefs(pattern *regexp.Regexp, text string, base PullRequestRef) []PullRequestRef {
+	var refs []PullRequestRef
+	seen := map[PullRequestRef]bool{}
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		number, err := strconv.Atoi(match[3])
+		if err != nil {
+			continue
+		}
+		ref := base
+		ref.Owner, ref.Repo, ref.Number = match[1], match[2], number
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// URL returns the link to the pull request, by which reviews refer to it
+func (r PullRequestRef) URL() string {
+	if r.Host == CodeHostGitLab {
+		return fmt.Sprintf("%s/%s/%s/-/merge_requests/%d", r.BaseURL, r.Owner, r.Repo, r.Number)
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/pull/%d", r.Owner, r.Repo, r.Number)
+}
+
+// SameAs reports whether both refer to the same pull request. Code hosts ignore the case of owners and repositories,
+// so links to a pull request may differ in case from the names its webhooks are sent with.
+func (r PullRequestRef) SameAs(other PullRequestRef) bool {
+	return r.Host == other.Host &&
+		strings.EqualFold(r.BaseURL, other.BaseURL) &&
+		strings.EqualFold(r.Owner, other.Owner) &&
+		strings.EqualFold(r.Repo, other.Repo) &&
+		r.Number == other.Number
+}
+
+func (r PullRequestRef) String() string {
+	if r.Host == CodeHostGitLab {
+		return fmt.Sprintf("%s/%s!%d", r.Owner, r.Repo, r.Number)
+	}
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// PullRequest is the metadata of a pull request or merge request shown to its reviewer
+type PullRequest struct {
+	PullRequestRef
+	Title string `json:"title"`
+	// Author is the login of whoever opened the pull request on its code host
+	Author string `json:"author"`
+	// Base is the branch the pull request is to be merged into, whose CODEOWNERS file applies to it
+	Base         string `json:"base"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+	ChangedFiles int    `json:"changed_files"`
+	// Paths are the files the pull request changes
+	Paths []string `json:"paths"`
+}
+
+// PullRequestAction is what happened to a pull request under review
+type PullRequestAction string
+
+const (
+	PullRequestActionApproved         PullRequestAction = "approved"
+	PullRequestActionChangesRequested PullRequestAction = "changes_requested"
+	PullRequestActionMerged           PullRequestAction = "merged"
+	PullRequestActionClosed           PullRequestAction = "closed"
+)
+
+// PullRequestEvent is a webhook delivery about a pull request under review
+type PullRequestEvent struct {
+	// DeliveryID identifies the delivery, which the code host keeps when redelivering it
+	DeliveryID string
+	PullRequestRef
+	Action PullRequestAction
+	// Sender is the login on the code host of whoever submitted the review or merged or closed the pull request
+	Sender string
+	// Link is the submitted review, or the pull request
+	Link string
+}
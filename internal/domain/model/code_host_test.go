@@ -0,0 +1,32 @@
+package model
+
+import "testing"
+
+func TestPullRequestRefSameAs(t *testing.T) {
+	webhook := PullRequestRef{Host: CodeHostGitHub, Owner: "Octo-Org", Repo: "Review-Bot", Number: 7}
+	tests := []struct {
+		name string
+		link string
+		want bool
+	}{
+		{name: "same casing", link: "https://github.com/Octo-Org/Review-Bot/pull/7", want: true},
+		{name: "other casing", link: "https://github.com/octo-org/review-bot/pull/7", want: true},
+		{name: "other number", link: "https://github.com/octo-org/review-bot/pull/8", want: false},
+		{name: "other repository", link: "https://github.com/octo-org/review-bot2/pull/7", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := FindGitHubPullRequestRefs(tt.link)
+			if len(refs) != 1 {
+				t.Fatalf("FindGitHubPullRequestRefs(%q) = %v", tt.link, refs)
+			}
+			if got := refs[0].SameAs(webhook); got != tt.want {
+				t.Errorf("SameAs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	gitLab := PullRequestRef{Host: CodeHostGitLab, BaseURL: "https://gitlab.com", Owner: "Octo-Org", Repo: "Review-Bot", Number: 7}
+	if gitLab.SameAs(webhook) {
+		t.Error("SameAs() = true for a merge request of GitLab")
+	}
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersPaths are where each code host looks for the CODEOWNERS file of a repository, in order
+var CodeOwnersPaths = map[CodeHost][]string{
+	CodeHostGitHub: {".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"},
+	CodeHostGitLab: {"CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"},
+}
+
+// codeOwnersRule is a line of a CODEOWNERS file
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	// owners are @users, @org/teams or email addresses; none means the paths are unowned
+	owners []string
+}
+
+// CodeOwners are the rules of a CODEOWNERS file
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners parses a CODEOWNERS file, skipping lines it cannot make sense of as GitHub does.
+// The sections of GitLab are read as one, without their default owners.
+func ParseCodeOwners(content string) *CodeOwners {
+	codeOwners := &CodeOwners{}
+	for line := range strings.Lines(content) {
+		fields := strings.Fields(stripCodeOwnersComment(line))
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		pattern, err := regexp.Compile(codeOwnersPatternRegexp(strings.ReplaceAll(fields[0], `\#`, "#")))
+		if err != nil {
+			continue
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return codeOwners
+}
+
+// stripCodeOwnersComment removes the comment from a line, which starts at a # that is not escaped
+func stripCodeOwnersComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// codeOwnersPatternRegexp translates a gitignore-style pattern into a regular expression matching the paths it covers
+func codeOwnersPatternRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	// Patterns without a slash but at their end match at any depth
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	switch {
+	case directory:
+		b.WriteString("/.*")
+	case strings.HasSuffix(pattern, "*") && !strings.HasSuffix(pattern, "**"):
+		// e.g. docs/* owns the files in docs but not those further down
+	default:
+		// A pattern naming a directory owns everything in it
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Owners returns the owners of the path, given by the last rule matching it
+func (c *CodeOwners) Owners(path string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// OwnersOf returns the owners of any of the paths in the order they first appear, each once
+func (c *CodeOwners) OwnersOf(paths []string) []string {
+	var owners []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		for _, owner := range c.Owners(path) {
+			if key := strings.ToLower(owner); !seen[key] {
+				seen[key] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// CodeOwnerLogin returns the login of an owner that is a user, as opposed to a team, a group or an email address.
+// GitLab groups without subgroups look like users and are told apart by not being anyone's login.
+func CodeOwnerLogin(owner string) (string, bool) {
+	login, ok := strings.CutPrefix(owner, "@")
+	if !ok || strings.Contains(login, "/") {
+		return "", false
+	}
+	return login, true
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// SlackIdentity is a member who signed in with Slack
+type SlackIdentity struct {
+	MemberID MemberID `json:"member_id"`
+	TeamID   string   `json:"team_id"`
+	Name     string   `json:"name"`
+}
+
+// ReviewerLoad is the number of open reviews assigned to a reviewer
+type ReviewerLoad struct {
+	Reviewer Member
+	Open     int
+}
+
+// Dashboard is an overview of the review requests
+type Dashboard struct {
+	// OpenReviews are the reviews not completed yet, oldest first
+	OpenReviews []*Review
+	// Load is the number of assigned reviews per reviewer, busiest first
+	Load []ReviewerLoad
+	// RecentCompletions are the reviews done since RecentSince, latest first
+	RecentCompletions []*Review
+	RecentSince       time.Time
+	GeneratedAt       time.Time
+
+	load map[MemberID]*ReviewerLoad
+}
+
+// NewDashboard starts a dashboard listing the completions of the recent period.
+// The reviews are given one at a time with Add, so that only those shown are kept, and Finish completes it.
+func NewDashboard(now time.Time, recent time.Duration) *Dashboard {
+	return &Dashboard{
+		RecentSince: now.Add(-recent),
+		GeneratedAt: now,
+		load:        make(map[MemberID]*ReviewerLoad),
+	}
+}
+
+// Add counts the review in, keeping it if the dashboard shows it
+func (d *Dashboard) Add(review *Review) {
+	switch {
+	case review.Status.IsDone():
+		if !review.FinishedAt().Before(d.RecentSince) {
+			d.RecentCompletions = append(d.RecentCompletions, review)
+		}
+	default:
+		d.OpenReviews = append(d.OpenReviews, review)
+		if review.Status.IsInReview() {
+			l, ok := d.load[review.Reviewer.MemberID]
+			if !ok {
+				l = &ReviewerLoad{Reviewer: review.Reviewer}
+				d.load[review.Reviewer.MemberID] = l
+			}
+			l.Open++
+		}
+	}
+}
+
+// Finish orders the reviews and the load once all reviews have been added
+func (d *Dashboard) Finish() {
+	sort.Slice(d.OpenReviews, func(i, j int) bool {
+		return d.OpenReviews[i].CreatedAt.Before(d.OpenReviews[j].CreatedAt)
+	})
+	sort.Slice(d.RecentCompletions, func(i, j int) bool {
+		return d.RecentCompletions[i].FinishedAt().After(d.RecentCompletions[j].FinishedAt())
+	})
+	for _, l := range d.load {
+		d.Load = append(d.Load, *l)
+	}
+	sort.Slice(d.Load, func(i, j int) bool {
+		if d.Load[i].Open != d.Load[j].Open {
+			return d.Load[i].Open > d.Load[j].Open
+		}
+		return d.Load[i].Reviewer.DisplayName < d.Load[j].Reviewer.DisplayName
+	})
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDashboard(t *testing.T) {
+	now := time.Now()
+	alice := Member{MemberID: "U1", DisplayName: "alice"}
+	bob := Member{MemberID: "U2", DisplayName: "bob"}
+	reviews := []*Review{
+		{ID: "old", Status: ReviewStatusCompleted, CompletedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "recent", Status: ReviewStatusMerged, ClosedAt: now.Add(-time.Hour)},
+		{ID: "pending", Status: ReviewStatusPending, CreatedAt: now.Add(-time.Hour)},
+		{ID: "bob", Status: ReviewStatusAssigned, Reviewer: bob, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "alice-1", Status: ReviewStatusAssigned, Reviewer: alice, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "alice-2", Status: ReviewStatusChangesRequested, Reviewer: alice, CreatedAt: now},
+	}
+	d := NewDashboard(now, 7*24*time.Hour)
+	for _, review := range reviews {
+		d.Add(review)
+	}
+	d.Finish()
+
+	var open []string
+	for _, review := range d.OpenReviews {
+		open = append(open, review.ID)
+	}
+	if want := []string{"bob", "alice-1", "pending", "alice-2"}; !slices.Equal(open, want) {
+		t.Errorf("open reviews = %v, want %v", open, want)
+	}
+	if len(d.RecentCompletions) != 1 || d.RecentCompletions[0].ID != "recent" {
+		t.Errorf("recent completions = %v, want only the recently merged review", d.RecentCompletions)
+	}
+	if len(d.Load) != 2 || d.Load[0].Reviewer != alice || d.Load[0].Open != 2 || d.Load[1].Open != 1 {
+		t.Errorf("load = %+v, want alice with 2 before bob with 1", d.Load)
+	}
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the encoding of exported review records
+type ExportFormat string
+
+const (
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSON is a single JSON array of records
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatNDJSON is one JSON record per line
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ParseExportFormat parses the export format; an empty string is CSV
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch format := ExportFormat(s); format {
+	case "":
+		return ExportFormatCSV, nil
+	case ExportFormatCSV, ExportFormatJSON, ExportFormatNDJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format %q: must be csv, json or ndjson", s)
+	}
+}
+
+// ContentType returns the media type of the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatJSON:
+		return "application/json"
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// ExportQuery selects the reviews to export by when they were requested; zero times do not restrict the result
+type ExportQuery struct {
+	From   time.Time
+	To     time.Time
+	Format ExportFormat
+}
+
+// Matches reports whether the review is selected by the query
+func (q ExportQuery) Matches(review *Review) bool {
+	if !q.From.IsZero() && review.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !review.CreatedAt.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// ReviewRecord is a review request as exported for analysis
+type ReviewRecord struct {
+	ID           string         `json:"id"`
+	ChannelID    string         `json:"channel_id"`
+	ThreadTS     string         `json:"thread_ts"`
+	Permalink    string         `json:"permalink,omitempty"`
+	RequesterID  MemberID       `json:"requester_id,omitempty"`
+	ExternalRef  string         `json:"external_ref,omitempty"`
+	ReviewerID   MemberID       `json:"reviewer_id,omitempty"`
+	ReviewerName string         `json:"reviewer_name,omitempty"`
+	Mode         AssignmentMode `json:"mode,omitempty"`
+	Status       ReviewStatus   `json:"status"`
+	RequestedAt  *time.Time     `json:"requested_at,omitempty"`
+	AssignedAt   *time.Time     `json:"assigned_at,omitempty"`
+	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
+	ClosedAt     *time.Time     `json:"closed_at,omitempty"`
+	UpdatedAt    *time.Time     `json:"updated_at,omitempty"`
+}
+
+// ReviewRecordCSVHeader is the header row of exported CSV files
+var ReviewRecordCSVHeader = []string{
+	"id", "channel_id", "thread_ts", "permalink", "requester_id", "reviewer_id", "reviewer_name",
+	"mode", "status", "requested_at", "assigned_at", "completed_at", "updated_at", "external_ref",
+	"closed_at",
+}
+
+// NewReviewRecord creates the record of the review, linking to its thread in the workspace if its URL is known
+func NewReviewRecord(review *Review, workspaceURL string) *ReviewRecord {
+	record := &ReviewRecord{
+		ID:           review.ID,
+		ChannelID:    review.ChannelID,
+		ThreadTS:     review.ThreadTS,
+		RequesterID:  review.RequesterID,
+		ExternalRef:  review.ExternalRef,
+		ReviewerID:   review.Reviewer.MemberID,
+		ReviewerName: review.Reviewer.DisplayName,
+		Mode:         review.Mode,
+		Status:       review.Status,
+		RequestedAt:  optionalTime(review.CreatedAt),
+		AssignedAt:   optionalTime(review.AssignedAt),
+		CompletedAt:  optionalTime(review.CompletedAt),
+		ClosedAt:     optionalTime(review.ClosedAt),
+		UpdatedAt:    optionalTime(review.UpdatedAt),
+	}
+	if workspaceURL != "" {
+		record.Permalink = ThreadPermalink(workspaceURL, review.ChannelID, review.ThreadTS)
+	}
+	return record
+}
+
+// CSVRow returns the fields of the record in the order of ReviewRecordCSVHeader.
+// Cells that a spreadsheet would run as a formula, such as a reviewer named "=HYPERLINK(...)", are quoted with a leading '.
+func (r *ReviewRecord) CSVRow() []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	row := []string{
+		r.ID, r.ChannelID, r.ThreadTS, r.Permalink, string(r.RequesterID), string(r.ReviewerID), r.ReviewerName,
+		string(r.Mode), string(r.Status),
+		formatTime(r.RequestedAt), formatTime(r.AssignedAt), formatTime(r.CompletedAt), formatTime(r.UpdatedAt),
+		r.ExternalRef, formatTime(r.ClosedAt),
+	}
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+	return row
+}
+
+// ThreadPermalink returns the link to the thread in the workspace, the inverse of ParseThreadLink
+func ThreadPermalink(workspaceURL, channelID, threadTS string) string {
+	return strings.TrimSuffix(workspaceURL, "/") + "/archives/" + channelID + "/p" + strings.Replace(threadTS, ".", "", 1)
+}
+
+// optionalTime returns nil for the zero time
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
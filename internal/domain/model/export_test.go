@@ -0,0 +1,42 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReviewRecordCSVRow(t *testing.T) {
+	closedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	review := &Review{
+		ID:          ReviewID("C1", "1.0"),
+		ChannelID:   "C1",
+		ThreadTS:    "1.0",
+		ExternalRef: "+1 org/repo#1",
+		Reviewer:    Member{MemberID: "U1", DisplayName: `=HYPERLINK("https://example.com")`},
+		Status:      ReviewStatusMerged,
+		ClosedAt:    closedAt,
+	}
+	row := NewReviewRecord(review, "").CSVRow()
+	if len(row) != len(ReviewRecordCSVHeader) {
+		t.Fatalf("CSVRow() has %d cells, header %d", len(row), len(ReviewRecordCSVHeader))
+	}
+	cell := func(name string) string {
+		return row[slices.Index(ReviewRecordCSVHeader, name)]
+	}
+	tests := []struct {
+		column string
+		want   string
+	}{
+		{column: "reviewer_name", want: `'=HYPERLINK("https://example.com")`},
+		{column: "external_ref", want: "'+1 org/repo#1"},
+		{column: "reviewer_id", want: "U1"},
+		{column: "closed_at", want: "2025-01-02T03:04:05Z"},
+		{column: "completed_at", want: ""},
+	}
+	for _, tt := range tests {
+		if got := cell(tt.column); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"regexp"
+)
+
+// pullRequestURLPattern matches links to GitHub pull requests, also within Slack's <url|label> formatting
+var pullRequestURLPattern = regexp.MustCompile(`https://github\.com/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/pull/([0-9]+)`)
+
+// FindGitHubPullRequestRefs returns the pull requests linked to in the text in the order they appear, each once
+func FindGitHubPullRequestRefs(text string) []PullRequestRef {
+	return findPullRequestRefs(pullRequestURLPattern, text, PullRequestRef{Host: CodeHostGitHub})
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GitLabInstance is a GitLab instance, whose merge requests are linked to below its base URL
+type GitLabInstance struct {
+	BaseURL string
+	// mergeRequestURLPattern matches links to merge requests, also within Slack's <url|label> formatting
+	mergeRequestURLPattern *regexp.Regexp
+}
+
+// NewGitLabInstance creates the GitLab instance served at the base URL, e.g. https://gitlab.com
+func NewGitLabInstance(baseURL string) *GitLabInstance {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &GitLabInstance{
+		BaseURL: baseURL,
+		// Namespaces may have subgroups, and the -/ separates the project from what is in it
+		mergeRequestURLPattern: regexp.MustCompile(regexp.QuoteMeta(baseURL) +
+			`/((?:[A-Za-z0-9_.-]+/)*[A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/-/merge_requests/([0-9]+)`),
+	}
+}
+
+// FindMergeRequestRefs returns the merge requests linked to in the text in the order they appear, each once
+func (g *GitLabInstance) FindMergeRequestRefs(text string) []PullRequestRef {
+	return findPullRequestRefs(g.mergeRequestURLPattern, text, PullRequestRef{Host: CodeHostGitLab, BaseURL: g.BaseURL})
+}
+
+// MergeRequestRef returns the merge request with the IID in the project with the full path, e.g. group/subgroup/project
+func (g *GitLabInstance) MergeRequestRef(projectPath string, iid int) PullRequestRef {
+	owner, repo := "", projectPath
+	if i := strings.LastIndex(projectPath, "/"); i >= 0 {
+		owner, repo = projectPath[:i], projectPath[i+1:]
+	}
+	return PullRequestRef{Host: CodeHostGitLab, BaseURL: g.BaseURL, Owner: owner, Repo: repo, Number: iid}
+}
+
+// ProjectPath returns the full path of the project of a merge request, by which the GitLab API finds it
+func (r PullRequestRef) ProjectPath() string {
+	return r.Owner + "/" + r.Repo
+}
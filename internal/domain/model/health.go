@@ -0,0 +1,44 @@
+package model
+
+// HealthStatus represents the result of a health check
+type HealthStatus string
+
+const (
+	HealthStatusOK   HealthStatus = "ok"
+	HealthStatusFail HealthStatus = "fail"
+)
+
+// HealthCheck is the result of checking a single dependency
+type HealthCheck struct {
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+}
+
+// HealthReport is the result of a liveness or readiness check, healthy only if all of its checks are
+type HealthReport struct {
+	Status HealthStatus           `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+func NewHealthReport() *HealthReport {
+	return &HealthReport{
+		Status: HealthStatusOK,
+		Checks: make(map[string]HealthCheck),
+	}
+}
+
+// Pass records a successful check
+func (r *HealthReport) Pass(name, message string) {
+	r.Checks[name] = HealthCheck{Status: HealthStatusOK, Message: message}
+}
+
+// Fail records a failed check, which makes the whole report fail
+func (r *HealthReport) Fail(name, message string) {
+	r.Checks[name] = HealthCheck{Status: HealthStatusFail, Message: message}
+	r.Status = HealthStatusFail
+}
+
+// Healthy reports whether all checks passed
+func (r *HealthReport) Healthy() bool {
+	return r.Status == HealthStatusOK
+}
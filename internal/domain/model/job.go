@@ -0,0 +1,26 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Job represents a unit of background work
+type Job struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Payload     []byte    `json:"payload"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	EnqueuedAt  time.Time `json:"enqueued_at"`
+	// TraceContext holds the propagated trace context of the request that enqueued the job
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// IsLastAttempt reports whether the current attempt is the last one before the job is dropped
+func (j *Job) IsLastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// JobHandler processes a job; returning an error schedules a retry until the attempts run out
+type JobHandler func(ctx context.Context, job *Job) error
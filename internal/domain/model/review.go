@@ -0,0 +1,220 @@
+package model
+
+import (
+	"time"
+)
+
+// ReviewStatus represents the state of a review request
+type ReviewStatus string
+
+const (
+	// ReviewStatusPending means the selection message is waiting for someone to pick a reviewer
+	ReviewStatusPending ReviewStatus = "pending"
+	// ReviewStatusAssigning means an interaction has claimed the review and a reviewer is being chosen
+	ReviewStatusAssigning ReviewStatus = "assigning"
+	// ReviewStatusAssigned means a reviewer has been assigned
+	ReviewStatusAssigned ReviewStatus = "assigned"
+	// ReviewStatusCompleted means the reviewer marked the review as done with a ✅ reaction
+	ReviewStatusCompleted ReviewStatus = "completed"
+	// ReviewStatusChangesRequested means changes were requested on the pull request, which stays with its reviewer
+	ReviewStatusChangesRequested ReviewStatus = "changes_requested"
+	// ReviewStatusApproved means the pull request was approved
+	ReviewStatusApproved ReviewStatus = "approved"
+	// ReviewStatusMerged means the pull request was merged
+	ReviewStatusMerged ReviewStatus = "merged"
+	// ReviewStatusClosed means the pull request was closed without being merged
+	ReviewStatusClosed ReviewStatus = "closed"
+)
+
+// IsInReview reports whether a reviewer is working on the review
+func (s ReviewStatus) IsInReview() bool {
+	return s == ReviewStatusAssigned || s == ReviewStatusChangesRequested
+}
+
+// IsDone reports whether nothing is left to do for the review
+func (s ReviewStatus) IsDone() bool {
+	switch s {
+	case ReviewStatusCompleted, ReviewStatusApproved, ReviewStatusMerged, ReviewStatusClosed:
+		return true
+	default:
+		return false
+	}
+}
+
+// AssignmentMode represents how a reviewer was chosen
+type AssignmentMode string
+
+const (
+	AssignmentModeRandom   AssignmentMode = "random"
+	AssignmentModeUrgent   AssignmentMode = "urgent"
+	AssignmentModeSelect   AssignmentMode = "select"
+	AssignmentModeReassign AssignmentMode = "reassign"
+	// AssignmentModeSuggested chooses at random among the code owners of the paths the pull request changes
+	AssignmentModeSuggested AssignmentMode = "suggested"
+)
+
+// AssignmentModeFromActionID returns the assignment mode triggered by an interactive action
+func AssignmentModeFromActionID(actionID string) (AssignmentMode, bool) {
+	switch actionID {
+	case "random_reviewer":
+		return AssignmentModeRandom, true
+	case "urgent_reviewer":
+		return AssignmentModeUrgent, true
+	case "select_reviewer":
+		return AssignmentModeSelect, true
+	case "reassign_reviewer":
+		return AssignmentModeReassign, true
+	case "suggested_reviewer":
+		return AssignmentModeSuggested, true
+	default:
+		return "", false
+	}
+}
+
+// claimTimeout is how long a claim may stay unfinished before another interaction may take over,
+// e.g. when the instance processing it was stopped
+const claimTimeout = 5 * time.Minute
+
+// Review represents a review request in a Slack thread
+type Review struct {
+	ID          string   `json:"id"`
+	ChannelID   string   `json:"channel_id"`
+	ThreadTS    string   `json:"thread_ts"`
+	RequesterID MemberID `json:"requester_id,omitempty"`
+	// ExternalRef is what is being reviewed outside Slack, such as the URL of a pull request
+	ExternalRef string         `json:"external_ref,omitempty"`
+	Status      ReviewStatus   `json:"status"`
+	Mode        AssignmentMode `json:"mode,omitempty"`
+	Reviewer    Member         `json:"reviewer"`
+	ClaimedBy   MemberID       `json:"claimed_by,omitempty"`
+	ClaimedAt   time.Time      `json:"claimed_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	AssignedAt  time.Time      `json:"assigned_at"`
+	CompletedAt time.Time      `json:"completed_at"`
+	// ClosedAt is when the pull request under review was merged or closed
+	ClosedAt  time.Time `json:"closed_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Version is incremented on every save and used for optimistic locking
+	Version int `json:"version"`
+}
+
+// ReviewID returns the ID of the review in the given thread
+func ReviewID(channelID, threadTS string) string {
+	return channelID + ":" + threadTS
+}
+
+func NewReview(channelID, threadTS string, requesterID MemberID, now time.Time) *Review {
+	return &Review{
+		ID:          ReviewID(channelID, threadTS),
+		ChannelID:   channelID,
+		ThreadTS:    threadTS,
+		RequesterID: requesterID,
+		Status:      ReviewStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// CanClaim reports whether an interaction with the given mode may claim the review.
+// currentReviewerName is the reviewer shown on the message for reassignments.
+func (r *Review) CanClaim(mode AssignmentMode, currentReviewerName string, now time.Time) bool {
+	switch r.Status {
+	case ReviewStatusPending:
+		return mode != AssignmentModeReassign
+	case ReviewStatusAssigned, ReviewStatusChangesRequested:
+		// Only the message of the current assignment can be reassigned
+		return mode == AssignmentModeReassign && r.Reviewer.DisplayName == currentReviewerName
+	case ReviewStatusAssigning:
+		return now.Sub(r.ClaimedAt) > claimTimeout
+	default:
+		return false
+	}
+}
+
+// Claim marks the review as being assigned by memberID
+func (r *Review) Claim(memberID MemberID, mode AssignmentMode, now time.Time) {
+	r.Status = ReviewStatusAssigning
+	r.Mode = mode
+	r.ClaimedBy = memberID
+	r.ClaimedAt = now
+	r.UpdatedAt = now
+}
+
+// Assign records the chosen reviewer
+func (r *Review) Assign(reviewer Member, now time.Time) {
+	r.Status = ReviewStatusAssigned
+	r.Reviewer = reviewer
+	r.AssignedAt = now
+	r.UpdatedAt = now
+}
+
+// Complete marks the assigned review as done
+func (r *Review) Complete(now time.Time) {
+	r.Status = ReviewStatusCompleted
+	r.CompletedAt = now
+	r.UpdatedAt = now
+}
+
+// FinishedAt returns when the review was done, or the zero time if it is not
+func (r *Review) FinishedAt() time.Time {
+	if !r.Status.IsDone() {
+		return time.Time{}
+	}
+	if r.CompletedAt.IsZero() {
+		return r.ClosedAt
+	}
+	return r.CompletedAt
+}
+
+// ApplyPullRequestEvent moves the review along with what happened to its pull request,
+// reporting false when the event does not change it
+func (r *Review) ApplyPullRequestEvent(action PullRequestAction, now time.Time) bool {
+	switch action {
+	case PullRequestActionApproved:
+		if !r.Status.IsInReview() {
+			return false
+		}
+		r.Status = ReviewStatusApproved
+		r.CompletedAt = now
+	case PullRequestActionChangesRequested:
+		if r.Status != ReviewStatusAssigned && r.Status != ReviewStatusApproved {
+			return false
+		}
+		r.Status = ReviewStatusChangesRequested
+		r.CompletedAt = time.Time{}
+	case PullRequestActionMerged, PullRequestActionClosed:
+		if r.Status == ReviewStatusMerged || r.Status == ReviewStatusClosed {
+			return false
+		}
+		r.Status = ReviewStatusMerged
+		if action == PullRequestActionClosed {
+			r.Status = ReviewStatusClosed
+		}
+		r.ClosedAt = now
+	default:
+		return false
+	}
+	r.UpdatedAt = now
+	return true
+}
+
+// CanReopen reports whether a new request in the thread may make the review selectable again,
+// which it may not while a reviewer is being assigned, is assigned or is done with it
+func (r *Review) CanReopen(now time.Time) bool {
+	switch r.Status {
+	case ReviewStatusPending:
+		return true
+	case ReviewStatusAssigning:
+		// A claim left unfinished must not keep the thread from being requested again
+		return now.Sub(r.ClaimedAt) > claimTimeout
+	default:
+		return false
+	}
+}
+
+// Reopen makes the review selectable again, e.g. after the assignment failed
+func (r *Review) Reopen(now time.Time) {
+	r.Status = ReviewStatusPending
+	r.ClaimedBy = ""
+	r.UpdatedAt = now
+}
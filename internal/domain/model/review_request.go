@@ -0,0 +1,146 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidReviewRequest is wrapped by the errors of review requests that cannot be made
+var ErrInvalidReviewRequest = errors.New("invalid review request")
+
+// ReviewRequest is a review request made through the API rather than by mentioning the bot
+type ReviewRequest struct {
+	ChannelID string `json:"channel"`
+	// ThreadTS is the thread to request the review in; without it, Text is posted as the review request
+	ThreadTS string `json:"thread_ts,omitempty"`
+	Text     string `json:"text,omitempty"`
+	// Mode is how to choose the reviewer, random by default
+	Mode AssignmentMode `json:"mode,omitempty"`
+	// Reviewers are the display names or member IDs to choose from, or the single reviewer to assign in select mode
+	Reviewers []string `json:"reviewers,omitempty"`
+	// RequesterID is the member asking for the review, who is never chosen
+	RequesterID MemberID `json:"requester_id,omitempty"`
+	// ExternalRef is what is being reviewed, such as the URL of a pull request, by which the review can be looked up
+	ExternalRef string `json:"external_ref,omitempty"`
+}
+
+// Validate checks the request and defaults its mode
+func (r *ReviewRequest) Validate() error {
+	if r.Mode == "" {
+		r.Mode = AssignmentModeRandom
+	}
+	switch {
+	case r.ChannelID == "":
+		return fmt.Errorf("%w: channel is required", ErrInvalidReviewRequest)
+	case r.ThreadTS == "" && r.Text == "":
+		return fmt.Errorf("%w: either thread_ts or text is required", ErrInvalidReviewRequest)
+	case r.ThreadTS != "" && r.Text != "":
+		return fmt.Errorf("%w: thread_ts and text cannot be given together", ErrInvalidReviewRequest)
+	case r.Mode != AssignmentModeRandom && r.Mode != AssignmentModeUrgent && r.Mode != AssignmentModeSelect && r.Mode != AssignmentModeSuggested:
+		return fmt.Errorf("%w: mode must be random, urgent, select or suggested", ErrInvalidReviewRequest)
+	case r.Mode == AssignmentModeSelect && len(r.Reviewers) != 1:
+		return fmt.Errorf("%w: select mode needs exactly one reviewer", ErrInvalidReviewRequest)
+	}
+	return nil
+}
+
+// ReviewRequestQuery finds a review request by exactly one of its ID, thread link or external reference
+type ReviewRequestQuery struct {
+	ID string
+	// ThreadLink is a link to the thread of the review request as ParseThreadLink accepts
+	ThreadLink  string
+	ExternalRef string
+	// Wait is how long to wait for the review request to change before answering
+	Wait time.Duration
+	// Version is the version the review request has to change from while waiting; zero means its current version
+	Version int
+}
+
+// Validate checks that the query names the review request in exactly one way
+func (q ReviewRequestQuery) Validate() error {
+	given := 0
+	for _, v := range []string{q.ID, q.ThreadLink, q.ExternalRef} {
+		if v != "" {
+			given++
+		}
+	}
+	switch {
+	case given != 1:
+		return fmt.Errorf("%w: exactly one of id, thread or external_ref is required", ErrInvalidReviewRequest)
+	case q.Wait < 0:
+		return fmt.Errorf("%w: wait cannot be negative", ErrInvalidReviewRequest)
+	case q.Version < 0:
+		return fmt.Errorf("%w: version cannot be negative", ErrInvalidReviewRequest)
+	}
+	return nil
+}
+
+// ReviewerAssignment is a reviewer assigned to a review, until ReplacedAt if they were reassigned
+type ReviewerAssignment struct {
+	Member
+	Mode       AssignmentMode `json:"mode,omitempty"`
+	AssignedAt time.Time      `json:"assigned_at"`
+	ReplacedAt *time.Time     `json:"replaced_at,omitempty"`
+}
+
+// Approval is a reviewer marking the review as done in Slack, or approving its pull request on its code host
+type Approval struct {
+	Member
+	// GitHubLogin is who approved the pull request on GitHub
+	GitHubLogin string `json:"github_login,omitempty"`
+	// GitLabUsername is who approved the merge request on GitLab
+	GitLabUsername string    `json:"gitlab_username,omitempty"`
+	ApprovedAt     time.Time `json:"approved_at"`
+}
+
+// ReviewDetail is a review request with its history, as answered by the review request API
+type ReviewDetail struct {
+	*ReviewRecord
+	// Version changes whenever the review does, so that clients can wait for the next change
+	Version   int                  `json:"version"`
+	Reviewers []ReviewerAssignment `json:"reviewers"`
+	Approvals []Approval           `json:"approvals"`
+}
+
+// NewReviewDetail creates the detail of the review from its audit events in the order they happened
+func NewReviewDetail(review *Review, events []*AuditEvent, workspaceURL string) *ReviewDetail {
+	detail := &ReviewDetail{
+		ReviewRecord: NewReviewRecord(review, workspaceURL),
+		Version:      review.Version,
+		Reviewers:    []ReviewerAssignment{},
+		Approvals:    []Approval{},
+	}
+	for _, event := range events {
+		switch event.Type {
+		case AuditEventAssigned, AuditEventReassigned:
+			if event.Reviewer == nil {
+				continue
+			}
+			if n := len(detail.Reviewers); n > 0 && detail.Reviewers[n-1].ReplacedAt == nil {
+				detail.Reviewers[n-1].ReplacedAt = &event.At
+			}
+			detail.Reviewers = append(detail.Reviewers, ReviewerAssignment{Member: *event.Reviewer, Mode: event.Mode, AssignedAt: event.At})
+		case AuditEventCompleted:
+			if event.Reviewer == nil {
+				continue
+			}
+			detail.Approvals = append(detail.Approvals, Approval{Member: *event.Reviewer, ApprovedAt: event.At})
+		case AuditEventPullRequestUpdated:
+			if event.Detail != string(PullRequestActionApproved) {
+				continue
+			}
+			approval := Approval{ApprovedAt: event.At}
+			if event.ExternalHost == CodeHostGitLab {
+				approval.GitLabUsername = event.ExternalActor
+			} else {
+				approval.GitHubLogin = event.ExternalActor
+			}
+			if event.Reviewer != nil {
+				approval.Member = *event.Reviewer
+			}
+			detail.Approvals = append(detail.Approvals, approval)
+		}
+	}
+	return detail
+}
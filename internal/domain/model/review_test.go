@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewCanReopen(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		status    ReviewStatus
+		claimedAt time.Time
+		want      bool
+	}{
+		{status: ReviewStatusPending, want: true},
+		{status: ReviewStatusAssigning, claimedAt: now.Add(-time.Minute), want: false},
+		{status: ReviewStatusAssigning, claimedAt: now.Add(-claimTimeout - time.Second), want: true},
+		{status: ReviewStatusAssigned, want: false},
+		{status: ReviewStatusChangesRequested, want: false},
+		{status: ReviewStatusApproved, want: false},
+		{status: ReviewStatusCompleted, want: false},
+		{status: ReviewStatusMerged, want: false},
+		{status: ReviewStatusClosed, want: false},
+	}
+	for _, tt := range tests {
+		review := &Review{Status: tt.status, ClaimedAt: tt.claimedAt}
+		if got := review.CanReopen(now); got != tt.want {
+			t.Errorf("CanReopen() of a %s review claimed at %v = %v, want %v", tt.status, tt.claimedAt, got, tt.want)
+		}
+	}
+}
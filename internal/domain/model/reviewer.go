@@ -0,0 +1,275 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReviewer is wrapped by the errors of reviewer configurations that cannot be used
+var ErrInvalidReviewer = errors.New("invalid reviewer")
+
+// memberIDPattern matches Slack member IDs such as U0123456 or W0123456
+var memberIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// gitHubLoginPattern matches GitHub logins: up to 39 alphanumerics or single hyphens, not starting with a hyphen
+var gitHubLoginPattern = regexp.MustCompile(`^[A-Za-z0-9](?:-?[A-Za-z0-9]){0,38}$`)
+
+// gitLabUsernamePattern matches GitLab usernames: alphanumerics, underscores, dots and hyphens,
+// starting with an alphanumeric or underscore and not ending with a dot
+var gitLabUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_](?:[A-Za-z0-9_.-]{0,253}[A-Za-z0-9_-])?$`)
+
+// Logins maps Slack member IDs to logins on a code host
+type Logins map[MemberID]string
+
+// CodeHostLogins are the logins of the reviewers on each code host
+type CodeHostLogins map[CodeHost]Logins
+
+// ValidateLogin checks the login of a reviewer on the code host
+func ValidateLogin(host CodeHost, login string) error {
+	pattern := gitHubLoginPattern
+	if host == CodeHostGitLab {
+		pattern = gitLabUsernamePattern
+	}
+	if !pattern.MatchString(login) {
+		return fmt.Errorf("%w: %q is not a %s login", ErrInvalidReviewer, login, host.Name())
+	}
+	return nil
+}
+
+// ValidateReviewer checks the display name and member ID of a reviewer
+func ValidateReviewer(displayName string, memberID MemberID) error {
+	switch {
+	case strings.TrimSpace(displayName) == "":
+		return fmt.Errorf("%w: display name of %s is empty", ErrInvalidReviewer, memberID)
+	case displayName != strings.TrimSpace(displayName):
+		return fmt.Errorf("%w: display name %q has leading or trailing spaces", ErrInvalidReviewer, displayName)
+	case !memberIDPattern.MatchString(string(memberID)):
+		return fmt.Errorf("%w: %q of %s is not a Slack member ID", ErrInvalidReviewer, memberID, displayName)
+	}
+	return nil
+}
+
+// Validate checks every reviewer and that no member appears under two display names
+func (r ReviewerMap) Validate() error {
+	names := make(map[MemberID]string, len(r))
+	for _, member := range r.Candidates(nil, nil) {
+		if err := ValidateReviewer(member.DisplayName, member.MemberID); err != nil {
+			return err
+		}
+		if other, ok := names[member.MemberID]; ok {
+			return fmt.Errorf("%w: %s is configured as both %s and %s", ErrInvalidReviewer, member.MemberID, other, member.DisplayName)
+		}
+		names[member.MemberID] = member.DisplayName
+	}
+	return nil
+}
+
+// Reviewer is a member of the reviewer roster
+type Reviewer struct {
+	Member
+	// Paused keeps the reviewer from being assigned, until PausedUntil if it is set
+	Paused      bool       `json:"paused"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+	// GitHubLogin is asked for reviews on GitHub when the reviewer is assigned to a pull request
+	GitHubLogin string `json:"github_login,omitempty"`
+	// GitLabUsername is set as a reviewer on GitLab when the reviewer is assigned to a merge request
+	GitLabUsername string    `json:"gitlab_username,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"`
+}
+
+// Login returns the login of the reviewer on the code host, or an empty string if there is none
+func (r *Reviewer) Login(host CodeHost) string {
+	if host == CodeHostGitLab {
+		return r.GitLabUsername
+	}
+	return r.GitHubLogin
+}
+
+// IsPaused reports whether the reviewer cannot be assigned at the time
+func (r *Reviewer) IsPaused(now time.Time) bool {
+	return r.Paused && (r.PausedUntil == nil || now.Before(*r.PausedUntil))
+}
+
+// Pause keeps the reviewer from being assigned until the time, or until resumed if it is zero
+func (r *Reviewer) Pause(until time.Time, now time.Time) {
+	r.Paused = true
+	r.PausedUntil = optionalTime(until)
+	r.UpdatedAt = now
+}
+
+// Resume makes the reviewer assignable again
+func (r *Reviewer) Resume(now time.Time) {
+	r.Paused = false
+	r.PausedUntil = nil
+	r.UpdatedAt = now
+}
+
+// Roster is the list of reviewers the bot assigns reviews to, sorted by display name
+type Roster struct {
+	Reviewers []*Reviewer `json:"reviewers"`
+}
+
+// NewRoster creates a roster of the reviewers in the map with their logins, none of them paused
+func NewRoster(reviewerMap ReviewerMap, logins CodeHostLogins) *Roster {
+	roster := &Roster{Reviewers: []*Reviewer{}}
+	for _, member := range reviewerMap.Candidates(nil, nil) {
+		roster.Reviewers = append(roster.Reviewers, &Reviewer{
+			Member:         member,
+			GitHubLogin:    logins[CodeHostGitHub][member.MemberID],
+			GitLabUsername: logins[CodeHostGitLab][member.MemberID],
+		})
+	}
+	return roster
+}
+
+// ReviewerMap returns every reviewer of the roster, paused or not
+func (r *Roster) ReviewerMap() ReviewerMap {
+	reviewerMap := make(ReviewerMap, len(r.Reviewers))
+	for _, reviewer := range r.Reviewers {
+		reviewerMap[reviewer.DisplayName] = reviewer.MemberID
+	}
+	return reviewerMap
+}
+
+// Available returns the reviewers that can be assigned at the time
+func (r *Roster) Available(now time.Time) ReviewerMap {
+	reviewerMap := make(ReviewerMap, len(r.Reviewers))
+	for _, reviewer := range r.Reviewers {
+		if !reviewer.IsPaused(now) {
+			reviewerMap[reviewer.DisplayName] = reviewer.MemberID
+		}
+	}
+	return reviewerMap
+}
+
+// Find returns the reviewer with the member ID
+func (r *Roster) Find(memberID MemberID) (*Reviewer, bool) {
+	for _, reviewer := range r.Reviewers {
+		if reviewer.MemberID == memberID {
+			return reviewer, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the reviewer with the display name or member ID
+func (r *Roster) Lookup(nameOrMemberID string) (*Reviewer, bool) {
+	for _, reviewer := range r.Reviewers {
+		if reviewer.DisplayName == nameOrMemberID || string(reviewer.MemberID) == nameOrMemberID {
+			return reviewer, true
+		}
+	}
+	return nil, false
+}
+
+// FindByLogin returns the reviewer with the login on the code host, which is case-insensitive
+func (r *Roster) FindByLogin(host CodeHost, login string) (*Reviewer, bool) {
+	for _, reviewer := range r.Reviewers {
+		if reviewer.Login(host) != "" && strings.EqualFold(reviewer.Login(host), login) {
+			return reviewer, true
+		}
+	}
+	return nil, false
+}
+
+// Add adds the reviewer to the roster
+func (r *Roster) Add(reviewer *Reviewer) {
+	r.Reviewers = append(r.Reviewers, reviewer)
+	r.sort()
+}
+
+// Rename changes the display name of the reviewer, keeping the roster sorted
+func (r *Roster) Rename(reviewer *Reviewer, displayName string, now time.Time) {
+	reviewer.DisplayName = displayName
+	reviewer.UpdatedAt = now
+	r.sort()
+}
+
+// SetLogin changes the login of the reviewer on the code host, removing it if empty
+func (r *Roster) SetLogin(reviewer *Reviewer, host CodeHost, login string, now time.Time) {
+	if host == CodeHostGitLab {
+		reviewer.GitLabUsername = login
+	} else {
+		reviewer.GitHubLogin = login
+	}
+	reviewer.UpdatedAt = now
+}
+
+// Remove removes the reviewer with the member ID, reporting whether there was one
+func (r *Roster) Remove(memberID MemberID) bool {
+	for i, reviewer := range r.Reviewers {
+		if reviewer.MemberID == memberID {
+			r.Reviewers = append(r.Reviewers[:i], r.Reviewers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the roster by the same rules as the reviewer configuration files,
+// which cannot repeat a display name in the first place
+func (r *Roster) Validate() error {
+	seen := make(map[string]bool, len(r.Reviewers))
+	logins := CodeHostLogins{CodeHostGitHub: {}, CodeHostGitLab: {}}
+	for _, reviewer := range r.Reviewers {
+		if seen[reviewer.DisplayName] {
+			return fmt.Errorf("%w: display name %s is used twice", ErrInvalidReviewer, reviewer.DisplayName)
+		}
+		seen[reviewer.DisplayName] = true
+		for host := range logins {
+			if login := reviewer.Login(host); login != "" {
+				logins[host][reviewer.MemberID] = login
+			}
+		}
+	}
+	if err := r.ReviewerMap().Validate(); err != nil {
+		return err
+	}
+	return logins.Validate(r.ReviewerMap())
+}
+
+// Validate checks the logins on every code host
+func (l CodeHostLogins) Validate(reviewers ReviewerMap) error {
+	for _, host := range AllCodeHosts {
+		if err := l[host].Validate(host, reviewers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that every login belongs to one of the reviewers, is a login on the code host and is not used twice
+func (l Logins) Validate(host CodeHost, reviewers ReviewerMap) error {
+	owners := make(map[string]MemberID, len(l))
+	members := make(map[MemberID]bool, len(reviewers))
+	for _, member := range reviewers.Candidates(nil, nil) {
+		members[member.MemberID] = true
+		login, ok := l[member.MemberID]
+		if !ok {
+			continue
+		}
+		if err := ValidateLogin(host, login); err != nil {
+			return err
+		}
+		if other, ok := owners[strings.ToLower(login)]; ok {
+			return fmt.Errorf("%w: %s login %s is configured for both %s and %s", ErrInvalidReviewer, host.Name(), login, other, member.MemberID)
+		}
+		owners[strings.ToLower(login)] = member.MemberID
+	}
+	for memberID := range l {
+		if !members[memberID] {
+			return fmt.Errorf("%w: %s login of %s who is not a reviewer", ErrInvalidReviewer, host.Name(), memberID)
+		}
+	}
+	return nil
+}
+
+func (r *Roster) sort() {
+	sort.Slice(r.Reviewers, func(i, j int) bool {
+		return r.Reviewers[i].DisplayName < r.Reviewers[j].DisplayName
+	})
+}
@@ -1,6 +1,13 @@
 package model
 
-import "math/rand"
+import (
+	"context"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
 
 // OAuthToken represents a Slack OAuth token
 type OAuthToken string
@@ -8,6 +15,23 @@ type OAuthToken string
 // SigningSecret represents a Slack signing secret
 type SigningSecret string
 
+// PreviousSigningSecret represents a rotated-out signing secret that is still accepted until it expires
+type PreviousSigningSecret struct {
+	Secret    SigningSecret `json:"secret"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// SigningSecrets represents the set of signing secrets accepted when verifying requests
+type SigningSecrets struct {
+	Primary  SigningSecret
+	Previous []PreviousSigningSecret
+}
+
+// Active reports whether the previous signing secret is still accepted at the given time
+func (p PreviousSigningSecret) Active(now time.Time) bool {
+	return p.Secret != "" && now.Before(p.ExpiresAt)
+}
+
 // MemberID represents a Slack member ID
 type MemberID string
 
@@ -16,19 +40,15 @@ type ReviewerMap map[string]MemberID
 
 // Member contains both the display name and member ID of a Slack member
 type Member struct {
-	DisplayName string
-	MemberID    MemberID
+	DisplayName string   `json:"display_name"`
+	MemberID    MemberID `json:"member_id"`
 }
 
-// GetRandomReviewer returns a random reviewer from the reviewer map.
+// Candidates returns the reviewers eligible for assignment, sorted by display name.
 // If filterMemberIDs is provided, it filters to only those member IDs.
 // If excludeMemberIDs is provided, it excludes those member IDs from selection.
 // If both are provided, it first filters then excludes.
-func (r ReviewerMap) GetRandomReviewer(filterMemberIDs []MemberID, excludeMemberIDs []MemberID) (Member, bool) {
-	if len(r) == 0 {
-		return Member{}, false
-	}
-
+func (r ReviewerMap) Candidates(filterMemberIDs []MemberID, excludeMemberIDs []MemberID) []Member {
 	// Create sets for efficient lookup
 	var filterSet map[MemberID]bool
 	if len(filterMemberIDs) > 0 {
@@ -60,6 +80,15 @@ func (r ReviewerMap) GetRandomReviewer(filterMemberIDs []MemberID, excludeMember
 			MemberID:    memberID,
 		})
 	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].DisplayName < candidates[j].DisplayName
+	})
+	return candidates
+}
+
+// GetRandomReviewer returns a random reviewer among the Candidates for the same arguments
+func (r ReviewerMap) GetRandomReviewer(filterMemberIDs []MemberID, excludeMemberIDs []MemberID) (Member, bool) {
+	candidates := r.Candidates(filterMemberIDs, excludeMemberIDs)
 	if len(candidates) == 0 {
 		return Member{}, false
 	}
@@ -68,6 +97,16 @@ func (r ReviewerMap) GetRandomReviewer(filterMemberIDs []MemberID, excludeMember
 	return selectedReviewer, true
 }
 
+// NameOf returns the display name of the reviewer with the member ID, or the member ID of others
+func (r ReviewerMap) NameOf(memberID MemberID) string {
+	for displayName, id := range r {
+		if id == memberID {
+			return displayName
+		}
+	}
+	return string(memberID)
+}
+
 // Action represents a Slack message action
 type Action struct {
 	Name    string `json:"name"`
@@ -98,11 +137,12 @@ type Attachment struct {
 
 // Message represents a Slack message
 type Message struct {
-	ChannelID       string       `json:"channel"`
+	ChannelID       string       `json:"channel,omitempty"`
 	Text            string       `json:"text,omitempty"`
 	Attachments     []Attachment `json:"attachments,omitempty"`
-	ReplaceOriginal bool         `json:"replace_original,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original"`
 	ThreadTS        string       `json:"thread_ts,omitempty"`
+	ResponseType    string       `json:"response_type,omitempty"`
 }
 
 func NewMessage(channelID, text string, attachments []Attachment, replaceOriginal bool, threadTS string) *Message {
@@ -115,58 +155,106 @@ func NewMessage(channelID, text string, attachments []Attachment, replaceOrigina
 	}
 }
 
+// NewEphemeralMessage creates a message that is only shown to the user who triggered an interaction.
+// It is sent as the response to the interaction and leaves the original message as is.
+func NewEphemeralMessage(text string) *Message {
+	return &Message{
+		Text:            text,
+		ReplaceOriginal: false,
+		ResponseType:    "ephemeral",
+	}
+}
+
 // Event represents a Slack event
 type Event interface {
-	Handle(handler EventHandler) *HTTPResponse
+	Handle(ctx context.Context, handler EventHandler) *HTTPResponse
 }
 
 // EventHandler defines the interface for handling different types of events
 type EventHandler interface {
-	HandleAppMention(event *AppMentionEvent) *HTTPResponse
-	HandleInteractiveMessage(event *InteractiveMessageEvent) *HTTPResponse
-	HandleURLVerification(event *URLVerificationEvent) *HTTPResponse
+	HandleAppMention(ctx context.Context, event *AppMentionEvent) *HTTPResponse
+	HandleInteractiveMessage(ctx context.Context, event *InteractiveMessageEvent) *HTTPResponse
+	HandleURLVerification(ctx context.Context, event *URLVerificationEvent) *HTTPResponse
+	HandleReactionAdded(ctx context.Context, event *ReactionAddedEvent) *HTTPResponse
+	HandleSlashCommand(ctx context.Context, event *SlashCommandEvent) *HTTPResponse
+}
+
+// IdempotentEvent is implemented by events that Slack may deliver more than once
+type IdempotentEvent interface {
+	Event
+	// IdempotencyKey returns a key that is the same for every delivery of the event, or an empty string if unknown
+	IdempotencyKey() string
 }
 
 // AppMentionEvent represents a Slack app mention event
 type AppMentionEvent struct {
+	EventID   string
+	TeamID    string
 	ChannelID string
 	ThreadTS  string
+	MemberID  MemberID
+	// Text is the text of the mentioning message, including the mention
+	Text string
 }
 
-func NewAppMentionEvent(channelID string, threadTS string) *AppMentionEvent {
+func NewAppMentionEvent(eventID, teamID, channelID, threadTS string, memberID MemberID, text string) *AppMentionEvent {
 	return &AppMentionEvent{
+		EventID:   eventID,
+		TeamID:    teamID,
 		ChannelID: channelID,
 		ThreadTS:  threadTS,
+		MemberID:  memberID,
+		Text:      text,
 	}
 }
 
-func (e *AppMentionEvent) Handle(handler EventHandler) *HTTPResponse {
-	return handler.HandleAppMention(e)
+func (e *AppMentionEvent) Handle(ctx context.Context, handler EventHandler) *HTTPResponse {
+	return handler.HandleAppMention(ctx, e)
+}
+
+func (e *AppMentionEvent) IdempotencyKey() string {
+	if e.EventID == "" {
+		return ""
+	}
+	return "event:" + e.EventID
 }
 
 // InteractiveMessageEvent represents a Slack interactive message event
 type InteractiveMessageEvent struct {
-	ChannelID string
-	ActionID  string
-	Value     string
-	MessageTS string
-	ThreadTS  string
-	MemberID  MemberID
+	TeamID      string
+	ChannelID   string
+	ActionID    string
+	ActionTS    string
+	Value       string
+	MessageTS   string
+	ThreadTS    string
+	MemberID    MemberID
+	ResponseURL string
 }
 
-func NewInteractiveMessageEvent(channelID, actionID, value, messageTS, threadTS string, memberID MemberID) *InteractiveMessageEvent {
+func NewInteractiveMessageEvent(teamID, channelID, actionID, actionTS, value, messageTS, threadTS string, memberID MemberID, responseURL string) *InteractiveMessageEvent {
 	return &InteractiveMessageEvent{
-		ChannelID: channelID,
-		ActionID:  actionID,
-		Value:     value,
-		MessageTS: messageTS,
-		ThreadTS:  threadTS,
-		MemberID:  memberID,
+		TeamID:      teamID,
+		ChannelID:   channelID,
+		ActionID:    actionID,
+		ActionTS:    actionTS,
+		Value:       value,
+		MessageTS:   messageTS,
+		ThreadTS:    threadTS,
+		MemberID:    memberID,
+		ResponseURL: responseURL,
 	}
 }
 
-func (e *InteractiveMessageEvent) Handle(handler EventHandler) *HTTPResponse {
-	return handler.HandleInteractiveMessage(e)
+func (e *InteractiveMessageEvent) Handle(ctx context.Context, handler EventHandler) *HTTPResponse {
+	return handler.HandleInteractiveMessage(ctx, e)
+}
+
+func (e *InteractiveMessageEvent) IdempotencyKey() string {
+	if e.ActionTS == "" {
+		return ""
+	}
+	return "interaction:" + e.ChannelID + ":" + e.MessageTS + ":" + string(e.MemberID) + ":" + e.ActionTS
 }
 
 // URLVerificationEvent represents a Slack URL verification event
@@ -180,6 +268,94 @@ func NewURLVerificationEvent(challenge string) *URLVerificationEvent {
 	}
 }
 
-func (e *URLVerificationEvent) Handle(handler EventHandler) *HTTPResponse {
-	return handler.HandleURLVerification(e)
+func (e *URLVerificationEvent) Handle(ctx context.Context, handler EventHandler) *HTTPResponse {
+	return handler.HandleURLVerification(ctx, e)
+}
+
+// ReactionAddedEvent represents a Slack reaction added event
+type ReactionAddedEvent struct {
+	EventID   string
+	TeamID    string
+	ChannelID string
+	// MessageTS is the timestamp of the message the reaction was added to
+	MessageTS string
+	MemberID  MemberID
+	Reaction  string
+}
+
+func NewReactionAddedEvent(eventID, teamID, channelID, messageTS string, memberID MemberID, reaction string) *ReactionAddedEvent {
+	return &ReactionAddedEvent{
+		EventID:   eventID,
+		TeamID:    teamID,
+		ChannelID: channelID,
+		MessageTS: messageTS,
+		MemberID:  memberID,
+		Reaction:  reaction,
+	}
+}
+
+func (e *ReactionAddedEvent) Handle(ctx context.Context, handler EventHandler) *HTTPResponse {
+	return handler.HandleReactionAdded(ctx, e)
+}
+
+func (e *ReactionAddedEvent) IdempotencyKey() string {
+	if e.EventID == "" {
+		return ""
+	}
+	return "event:" + e.EventID
+}
+
+// SlashCommandEvent represents an invocation of a slash command such as /review
+type SlashCommandEvent struct {
+	TeamID    string
+	ChannelID string
+	MemberID  MemberID
+	Command   string
+	// Text is what follows the command
+	Text        string
+	ResponseURL string
+}
+
+func NewSlashCommandEvent(teamID, channelID string, memberID MemberID, command, text, responseURL string) *SlashCommandEvent {
+	return &SlashCommandEvent{
+		TeamID:      teamID,
+		ChannelID:   channelID,
+		MemberID:    memberID,
+		Command:     command,
+		Text:        text,
+		ResponseURL: responseURL,
+	}
+}
+
+func (e *SlashCommandEvent) Handle(ctx context.Context, handler EventHandler) *HTTPResponse {
+	return handler.HandleSlashCommand(ctx, e)
+}
+
+// ParseThreadLink returns the channel and thread timestamp of a Slack message link such as
+// https://example.slack.com/archives/C0123456/p1700000000123456?thread_ts=1700000000.000100.
+// The link may be wrapped in angle brackets and carry a label as Slack formats links in message text.
+func ParseThreadLink(link string) (channelID, threadTS string, ok bool) {
+	link = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(link), "<"), ">")
+	if i := strings.Index(link, "|"); i >= 0 {
+		link = link[:i]
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "archives" || parts[1] == "" {
+		return "", "", false
+	}
+	// The message timestamp is written without the dot, e.g. p1700000000123456 for 1700000000.123456
+	digits := strings.TrimPrefix(parts[2], "p")
+	if len(digits) <= 6 || digits == parts[2] {
+		return "", "", false
+	}
+	threadTS = digits[:len(digits)-6] + "." + digits[len(digits)-6:]
+	// Replies link to themselves and name their thread in the query
+	if ts := u.Query().Get("thread_ts"); ts != "" {
+		threadTS = ts
+	}
+	return parts[1], threadTS, true
 }
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStatsPeriod is the period of the statistics when none is given
+const DefaultStatsPeriod = 7 * 24 * time.Hour
+
+// StatsPeriod is the time range of review requests the statistics are about
+type StatsPeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ParseStatsPeriod parses a period ending now such as 24h, 7d or 4w; an empty string is DefaultStatsPeriod
+func ParseStatsPeriod(s string, now time.Time) (StatsPeriod, error) {
+	if s == "" {
+		return StatsPeriod{From: now.Add(-DefaultStatsPeriod), To: now}, nil
+	}
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if !ok || err != nil || n <= 0 {
+		return StatsPeriod{}, fmt.Errorf("invalid period %q: must be a positive number of hours, days or weeks such as 24h, 7d or 4w", s)
+	}
+	return StatsPeriod{From: now.Add(-time.Duration(n) * unit), To: now}, nil
+}
+
+// DurationStats summarizes how long something took across reviews
+type DurationStats struct {
+	Count  int
+	Median time.Duration
+	P90    time.Duration
+}
+
+func (s DurationStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Count         int     `json:"count"`
+		MedianSeconds float64 `json:"median_seconds"`
+		P90Seconds    float64 `json:"p90_seconds"`
+	}{s.Count, s.Median.Seconds(), s.P90.Seconds()})
+}
+
+// newDurationStats returns the median and 90th percentile of the durations by the nearest-rank method
+func newDurationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	percentile := func(p float64) time.Duration {
+		return durations[int(math.Ceil(p*float64(len(durations))))-1]
+	}
+	return DurationStats{
+		Count:  len(durations),
+		Median: percentile(0.5),
+		P90:    percentile(0.9),
+	}
+}
+
+// PersonStats counts the review requests of a member, as requester and as reviewer
+type PersonStats struct {
+	MemberID MemberID `json:"member_id"`
+	// DisplayName is the reviewer name the member was assigned under, if ever
+	DisplayName string `json:"display_name,omitempty"`
+	Requested   int    `json:"requested"`
+	Assigned    int    `json:"assigned"`
+	Completed   int    `json:"completed"`
+}
+
+// ReviewStats are the statistics of the review requests made in a period
+type ReviewStats struct {
+	Period    StatsPeriod `json:"period"`
+	Requested int         `json:"requested"`
+	Assigned  int         `json:"assigned"`
+	Completed int         `json:"completed"`
+	// TimeToFirstResponse is the time from the request to the first assignment of a reviewer
+	TimeToFirstResponse DurationStats `json:"time_to_first_response"`
+	// TimeToCompletion is the time from the request to the ✅ of the reviewer
+	TimeToCompletion DurationStats `json:"time_to_completion"`
+	// Reassigned is the number of assigned requests that were reassigned at least once
+	Reassigned   int     `json:"reassigned"`
+	ReassignRate float64 `json:"reassign_rate"`
+	// UrgentAttempts is how often Urgent was clicked and UrgentHits how often it found an online reviewer
+	UrgentAttempts int           `json:"urgent_attempts"`
+	UrgentHits     int           `json:"urgent_hits"`
+	UrgentHitRate  float64       `json:"urgent_hit_rate"`
+	People         []PersonStats `json:"people"`
+}
+
+// reviewHistory is what the audit log tells about a single review request
+type reviewHistory struct {
+	requesterID     MemberID
+	requestedAt     time.Time
+	firstAssignedAt time.Time
+	completedAt     time.Time
+	reviewer        *Member
+	reassigned      bool
+	completedBy     *Member
+}
+
+// NewReviewStats computes the statistics of the review requests made in the period from their audit events.
+// Events after the period are still taken into account for the requests made in it, e.g. later completions.
+func NewReviewStats(events []*AuditEvent, period StatsPeriod) *ReviewStats {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	histories := make(map[string]*reviewHistory)
+	var order []string
+	stats := &ReviewStats{Period: period}
+	for _, event := range events {
+		if event.ReviewID == "" {
+			continue
+		}
+		h, ok := histories[event.ReviewID]
+		if !ok {
+			h = &reviewHistory{}
+			histories[event.ReviewID] = h
+			order = append(order, event.ReviewID)
+		}
+		switch event.Type {
+		case AuditEventSelectionPosted, AuditEventRequested:
+			// Restored selection messages carry a detail and are not new requests
+			if h.requestedAt.IsZero() && event.Detail == "" {
+				h.requesterID = event.ActorID
+				h.requestedAt = event.At
+			}
+		case AuditEventClicked:
+			if event.Mode == AssignmentModeUrgent && event.Detail == "" && inPeriod(h, period) {
+				stats.UrgentAttempts++
+			}
+		case AuditEventAssigned, AuditEventReassigned:
+			if h.firstAssignedAt.IsZero() {
+				h.firstAssignedAt = event.At
+			}
+			if event.Type == AuditEventReassigned {
+				h.reassigned = true
+			}
+			h.reviewer = event.Reviewer
+			if event.Mode == AssignmentModeUrgent && inPeriod(h, period) {
+				stats.UrgentHits++
+			}
+		case AuditEventCompleted:
+			if h.completedAt.IsZero() {
+				h.completedAt = event.At
+				h.completedBy = event.Reviewer
+			}
+		}
+	}
+
+	people := make(map[MemberID]*PersonStats)
+	person := func(memberID MemberID) *PersonStats {
+		p, ok := people[memberID]
+		if !ok {
+			p = &PersonStats{MemberID: memberID}
+			people[memberID] = p
+		}
+		return p
+	}
+	var firstResponses, completions []time.Duration
+	for _, id := range order {
+		h := histories[id]
+		if !inPeriod(h, period) {
+			continue
+		}
+		stats.Requested++
+		if h.requesterID != "" {
+			person(h.requesterID).Requested++
+		}
+		if !h.firstAssignedAt.IsZero() {
+			stats.Assigned++
+			firstResponses = append(firstResponses, h.firstAssignedAt.Sub(h.requestedAt))
+			if h.reassigned {
+				stats.Reassigned++
+			}
+		}
+		if h.reviewer != nil {
+			p := person(h.reviewer.MemberID)
+			p.DisplayName = h.reviewer.DisplayName
+			p.Assigned++
+		}
+		if !h.completedAt.IsZero() {
+			stats.Completed++
+			completions = append(completions, h.completedAt.Sub(h.requestedAt))
+			if h.completedBy != nil {
+				person(h.completedBy.MemberID).Completed++
+			}
+		}
+	}
+	stats.TimeToFirstResponse = newDurationStats(firstResponses)
+	stats.TimeToCompletion = newDurationStats(completions)
+	stats.ReassignRate = rate(stats.Reassigned, stats.Assigned)
+	stats.UrgentHitRate = rate(stats.UrgentHits, stats.UrgentAttempts)
+
+	stats.People = make([]PersonStats, 0, len(people))
+	for _, p := range people {
+		stats.People = append(stats.People, *p)
+	}
+	sort.Slice(stats.People, func(i, j int) bool {
+		a, b := stats.People[i], stats.People[j]
+		if a.Requested+a.Assigned != b.Requested+b.Assigned {
+			return a.Requested+a.Assigned > b.Requested+b.Assigned
+		}
+		return strings.Compare(string(a.MemberID), string(b.MemberID)) < 0
+	})
+	return stats
+}
+
+// inPeriod reports whether the review was requested in the period
+func inPeriod(h *reviewHistory, period StatsPeriod) bool {
+	return !h.requestedAt.IsZero() && !h.requestedAt.Before(period.From) && h.requestedAt.Before(period.To)
+}
+
+// rate returns n / total, or zero without any total
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+	// Append records the event and sets its ID; recorded events are never changed, only removed once past the retention
+	Append(event *model.AuditEvent) error
+	// List returns the events selected by the query in the order they happened, at most query.Limit of them if set
+	List(query model.AuditQuery) ([]*model.AuditEvent, error)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// ErrCodeHostDisabled is returned when no API token, or no webhook secret for webhooks, is configured for a code host
+var ErrCodeHostDisabled = errors.New("code host integration is disabled")
+
+// CodeHost defines the interface for the API of a code host, such as GitHub or GitLab
+type CodeHost interface {
+	// Host tells which code host this is
+	Host() model.CodeHost
+	// FindPullRequestRefs returns the pull requests of the code host linked to in the text in the order they appear
+	FindPullRequestRefs(text string) []model.PullRequestRef
+	// GetPullRequest returns the metadata of the pull request, including the paths it changes
+	GetPullRequest(ctx context.Context, ref model.PullRequestRef) (*model.PullRequest, error)
+	// GetCodeOwners returns the CODEOWNERS file of the repository at the ref, or nil if it has none
+	GetCodeOwners(ctx context.Context, ref model.PullRequestRef, gitRef string) (*model.CodeOwners, error)
+	// RequestReviewer asks the login for a review of the pull request
+	RequestReviewer(ctx context.Context, ref model.PullRequestRef, login string) error
+	// RemoveRequestedReviewer withdraws the request for a review of the pull request from the login
+	RemoveRequestedReviewer(ctx context.Context, ref model.PullRequestRef, login string) error
+	// VerifyWebhook checks that the webhook delivery comes from the code host with the webhook secret
+	VerifyWebhook(r *model.HTTPRequest) error
+	// ParseWebhook parses a verified webhook delivery. It returns nil for deliveries about anything
+	// but approvals, change requests and merged or closed pull requests.
+	ParseWebhook(r *model.HTTPRequest) (*model.PullRequestEvent, error)
+}
+
+// CodeHosts are the code hosts pull requests are looked for on, in order
+type CodeHosts []CodeHost
+
+// Get returns the code host of the kind
+func (c CodeHosts) Get(host model.CodeHost) (CodeHost, bool) {
+	for _, codeHost := range c {
+		if codeHost.Host() == host {
+			return codeHost, true
+		}
+	}
+	return nil, false
+}
+
+// FindPullRequestRefs returns the pull requests of any of the code hosts linked to in the text
+func (c CodeHosts) FindPullRequestRefs(text string) []model.PullRequestRef {
+	var refs []model.PullRequestRef
+	for _, codeHost := range c {
+		refs = append(refs, codeHost.FindPullRequestRefs(text)...)
+	}
+	return refs
+}
+
+// ParsePullRequestURL returns the pull request of a URL written by PullRequestRef.URL on any of the code hosts,
+// along with its code host
+func (c CodeHosts) ParsePullRequestURL(url string) (model.PullRequestRef, CodeHost, bool) {
+	for _, codeHost := range c {
+		refs := codeHost.FindPullRequestRefs(url)
+		if len(refs) == 1 && refs[0].URL() == url {
+			return refs[0], codeHost, true
+		}
+	}
+	return model.PullRequestRef{}, nil, false
+}
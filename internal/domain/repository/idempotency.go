@@ -0,0 +1,10 @@
+package repository
+
+// IdempotencyRepository defines the interface for recording which events have already been handled
+type IdempotencyRepository interface {
+	// Claim records the key and reports whether it was not recorded yet.
+	// Keys expire after a TTL so that the store does not grow forever.
+	Claim(key string) (bool, error)
+	// Release forgets the key so that a redelivery of the event is handled again
+	Release(key string) error
+}
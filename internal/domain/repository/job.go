@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// JobRepository defines the interface for persisting queued jobs so that they survive restarts
+type JobRepository interface {
+	// Save creates or updates a job
+	Save(job *model.Job) error
+	// Delete removes a finished job
+	Delete(id string) error
+	// List returns all unfinished jobs in the order they were enqueued
+	List() ([]*model.Job, error)
+}
+
+// JobQueue defines the interface for running jobs in the background
+type JobQueue interface {
+	// Register sets the handler for jobs of the given type
+	Register(jobType string, handler model.JobHandler)
+	// Enqueue schedules a job of the given type with the given payload.
+	// The job carries the trace context of ctx so that its spans can be linked to the enqueuing request.
+	Enqueue(ctx context.Context, jobType string, payload []byte) error
+}
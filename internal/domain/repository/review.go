@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+var (
+	// ErrReviewNotFound is returned when no review exists with the given ID
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrReviewConflict is returned when a review was modified since it was read
+	ErrReviewConflict = errors.New("review was modified concurrently")
+)
+
+// ReviewRepository defines the interface for storing review requests
+type ReviewRepository interface {
+	// Get returns the review with the given ID, or ErrReviewNotFound
+	Get(id string) (*model.Review, error)
+	// Save creates or updates a review and increments its version.
+	// It returns ErrReviewConflict when the stored version differs from the review's version.
+	Save(review *model.Review) error
+	// List returns all reviews ordered by ID
+	List() ([]*model.Review, error)
+	// Each calls fn for every review ordered by ID, without loading all of them at once.
+	// fn runs outside of the reads of the store, so it may take its time and save reviews.
+	Each(fn func(review *model.Review) error) error
+	// Ping reports whether the underlying store can be reached
+	Ping() error
+}
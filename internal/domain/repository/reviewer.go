@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// ReviewerRepository defines the interface for storing the reviewer roster
+type ReviewerRepository interface {
+	// GetRoster returns the current roster
+	GetRoster() (*model.Roster, error)
+	// UpdateRoster atomically replaces the roster with the one modified by fn; nothing is stored if fn fails
+	UpdateRoster(fn func(roster *model.Roster) error) error
+}
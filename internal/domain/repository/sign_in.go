@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// SignInRepository signs members in with Slack through OpenID Connect
+type SignInRepository interface {
+	// AuthorizationURL returns where to send the browser to sign in, carrying state and nonce through the flow
+	AuthorizationURL(state, nonce string) string
+	// Exchange redeems the authorization code and returns who signed in, checking that the ID token carries nonce
+	Exchange(ctx context.Context, code, nonce string) (*model.SlackIdentity, error)
+}
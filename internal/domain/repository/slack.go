@@ -1,21 +1,39 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
 )
 
 // SlackRepository defines the interface for Slack operations
 type SlackRepository interface {
 	// VerifyRequest validates the incoming request
-	VerifyRequest(r *model.HTTPRequest) error
+	VerifyRequest(ctx context.Context, r *model.HTTPRequest) error
 	// ParseEvent parses the raw event data into a domain event
-	ParseEvent(body []byte) (model.Event, error)
+	ParseEvent(ctx context.Context, body []byte) (model.Event, error)
 	// ParseInteraction parses the raw interaction data into a domain event
-	ParseInteraction(body []byte) (model.Event, error)
-	// PostMessage posts a message to a Slack channel
-	PostMessage(message *model.Message) error
-	// DeleteMessage deletes a message from a Slack channel
-	DeleteMessage(channelID, timestamp string) error
+	ParseInteraction(ctx context.Context, body []byte) (model.Event, error)
+	// ParseCommand parses the raw slash command data into a domain event
+	ParseCommand(ctx context.Context, body []byte) (model.Event, error)
+	// PostMessage posts a message to a Slack channel and returns its timestamp
+	PostMessage(ctx context.Context, message *model.Message) (string, error)
+	// ReplaceMessage replaces the message at timestamp, through the interaction's response URL if given
+	ReplaceMessage(ctx context.Context, message *model.Message, timestamp, responseURL string) error
+	// GetThreadText returns the text of the message that starts the thread
+	GetThreadText(ctx context.Context, channelID, threadTS string) (string, error)
 	// FilterOnlineMemberIDs returns a list of online member IDs from the specified member IDs
-	FilterOnlineMemberIDs(memberIDs []model.MemberID) ([]model.MemberID, error)
+	FilterOnlineMemberIDs(ctx context.Context, memberIDs []model.MemberID) ([]model.MemberID, error)
+	// GetUserLocale returns the locale the member uses Slack in, such as en-US
+	GetUserLocale(ctx context.Context, memberID model.MemberID) (string, error)
+	// TestAuth checks that the OAuth token is accepted by Slack
+	TestAuth(ctx context.Context) error
+	// GetWorkspaceURL returns the URL of the workspace, such as https://example.slack.com/
+	GetWorkspaceURL(ctx context.Context) (string, error)
+}
+
+// SigningSecretRepository provides the signing secrets currently accepted for request verification
+type SigningSecretRepository interface {
+	// GetSigningSecrets returns the current set of signing secrets
+	GetSigningSecrets() model.SigningSecrets
 }
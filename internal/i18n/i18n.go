@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Language is a language the bot writes its messages in
+type Language string
+
+const (
+	Japanese Language = "ja"
+	English  Language = "en"
+)
+
+// Languages are the languages there is a bundle of messages for, the first being the default
+var Languages = []Language{Japanese, English}
+
+// ParseLanguage returns the language of a language tag such as en or of a Slack locale such as en-US.
+// It reports false for languages without a bundle.
+func ParseLanguage(tag string) (Language, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	language := Language(base)
+	return language, slices.Contains(Languages, language)
+}
+
+//go:embed locales/*.json
+var bundles embed.FS
+
+// message is a message of a bundle. Messages depending on a count have a form for a count of one
+// as well as for any other count; the others only have the latter.
+type message struct {
+	One   string `json:"one"`
+	Other string `json:"other"`
+}
+
+func (m *message) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &m.Other); err == nil {
+		return nil
+	}
+	type plural message
+	return json.Unmarshal(b, (*plural)(m))
+}
+
+// Catalog holds the bundles of messages of all languages
+type Catalog struct {
+	bundles map[Language]map[string]message
+}
+
+// NewCatalog loads the bundles embedded in the binary, checking that every language has the same messages
+func NewCatalog() (*Catalog, error) {
+	c := &Catalog{bundles: make(map[Language]map[string]message, len(Languages))}
+	for _, language := range Languages {
+		content, err := bundles.ReadFile("locales/" + string(language) + ".json")
+		if err != nil {
+			return nil, err
+		}
+		var bundle map[string]message
+		if err := json.Unmarshal(content, &bundle); err != nil {
+			return nil, fmt.Errorf("malformed %s bundle: %w", language, err)
+		}
+		for id, m := range bundle {
+			if m.Other == "" {
+				return nil, fmt.Errorf("message %s of the %s bundle is empty", id, language)
+			}
+		}
+		c.bundles[language] = bundle
+	}
+	// Every bundle is checked against the default one so that no message goes missing in any language
+	reference := c.bundles[Languages[0]]
+	var errs []error
+	for _, language := range Languages[1:] {
+		for _, id := range slices.Sorted(maps.Keys(reference)) {
+			if _, ok := c.bundles[language][id]; !ok {
+				errs = append(errs, fmt.Errorf("message %s is missing from the %s bundle", id, language))
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(c.bundles[language])) {
+			if _, ok := reference[id]; !ok {
+				errs = append(errs, fmt.Errorf("message %s of the %s bundle is unknown", id, language))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Localizer returns the localizer of the language, or of the default language if there is no bundle for it
+func (c *Catalog) Localizer(language Language) *Localizer {
+	bundle, ok := c.bundles[language]
+	if !ok {
+		language = Languages[0]
+		bundle = c.bundles[language]
+	}
+	return &Localizer{language: language, messages: bundle}
+}
+
+// Localizer writes the messages of a language.
+// Messages are fmt formats whose arguments are indexed, e.g. %[1]s, so that translations may reorder them.
+type Localizer struct {
+	language Language
+	messages map[string]message
+}
+
+func (l *Localizer) Language() Language {
+	return l.language
+}
+
+// T formats the message with the arguments, or returns its ID if there is no such message
+func (l *Localizer) T(id string, args ...any) string {
+	m, ok := l.messages[id]
+	if !ok {
+		return id
+	}
+	return fmt.Sprintf(m.Other, args...)
+}
+
+// Plural formats the form of the message for the count, which is the first argument followed by args
+func (l *Localizer) Plural(id string, count int, args ...any) string {
+	m, ok := l.messages[id]
+	if !ok {
+		return id
+	}
+	format := m.Other
+	if m.One != "" && l.isOne(count) {
+		format = m.One
+	}
+	return fmt.Sprintf(format, append([]any{count}, args...)...)
+}
+
+// isOne tells whether the count takes the singular form. Japanese does not inflect for number.
+func (l *Localizer) isOne(count int) bool {
+	return l.language == English && count == 1
+}
+
+// List joins the items into a list as written in running text, e.g. "A, B and C"
+func (l *Localizer) List(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], l.T("list.separator")) + l.T("list.last_separator") + items[len(items)-1]
+}
@@ -0,0 +1,49 @@
+package i18n
+
+import "testing"
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Language
+		ok   bool
+	}{
+		{tag: "en-US", want: English, ok: true},
+		{tag: "ja_JP", want: Japanese, ok: true},
+		{tag: " EN ", want: English, ok: true},
+		{tag: "fr-FR", want: "fr", ok: false},
+		{tag: "", want: "", ok: false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseLanguage(tt.tag); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseLanguage(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog, err := NewCatalog()
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	en := catalog.Localizer(English)
+	ja := catalog.Localizer(Japanese)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "en message", got: en.T("assignment.notification", "<@U1>"), want: "<@U1>, you have been asked to review this"},
+		{name: "ja message", got: ja.T("assignment.notification", "<@U1>"), want: "<@U1> さん、レビューをお願いします"},
+		{name: "en plural of one", got: en.Plural("pull_request.more_files", 1), want: "and 1 more file"},
+		{name: "en plural of several", got: en.Plural("pull_request.more_files", 2), want: "and 2 more files"},
+		{name: "ja plural", got: ja.Plural("pull_request.more_files", 1), want: "ほか 1 件"},
+		{name: "en list", got: en.List([]string{"A", "B", "C"}), want: "A, B and C"},
+		{name: "unknown message", got: en.T("no.such.message"), want: "no.such.message"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+const auditBucket = "audit"
+
+// auditKeyTimeFormat makes the keys sort in the order the events happened
+const auditKeyTimeFormat = "20060102T150405.000000000Z"
+
+// auditBatchSize is how many events List and the purge read at once
+const auditBatchSize = 100
+
+// auditPurgeInterval is how often events older than the retention are removed from the store
+const auditPurgeInterval = time.Hour
+
+// AuditStore is an AuditRepository backed by a KVStore
+type AuditStore struct {
+	kv KVStore
+	// retention is how long events are kept; zero keeps them forever
+	retention time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+var _ repository.AuditRepository = (*AuditStore)(nil)
+
+func NewAuditStore(kv KVStore, retention time.Duration) *AuditStore {
+	return &AuditStore{
+		kv:        kv,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func (s *AuditStore) Append(event *model.AuditEvent) error {
+	s.purgeExpired(s.now())
+	id := newID()
+	stored := *event
+	stored.ID = id
+	b, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Put(auditBucket, auditKey(event.At)+"-"+id, b); err != nil {
+		return err
+	}
+	event.ID = id
+	return nil
+}
+
+// List seeks to the first key at or after query.From rather than reading the whole log
+// and stops at query.To or once query.Limit events have been selected
+func (s *AuditStore) List(query model.AuditQuery) ([]*model.AuditEvent, error) {
+	var events []*model.AuditEvent
+	// Keys are the time followed by a dash, so they all sort after the time alone
+	after, to := "", ""
+	if !query.From.IsZero() {
+		after = auditKey(query.From)
+	}
+	if !query.To.IsZero() {
+		to = auditKey(query.To)
+	}
+	for {
+		n, done := 0, false
+		err := s.kv.Scan(auditBucket, after, auditBatchSize, func(key string, value []byte) error {
+			n++
+			after = key
+			if done || (to != "" && key >= to) || (query.Limit > 0 && len(events) >= query.Limit) {
+				done = true
+				return nil
+			}
+			var event model.AuditEvent
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+			if query.Matches(&event) {
+				events = append(events, &event)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if done || n < auditBatchSize || (query.Limit > 0 && len(events) >= query.Limit) {
+			return events, nil
+		}
+	}
+}
+
+// purgeExpired removes the events older than the retention at most once per purge interval
+func (s *AuditStore) purgeExpired(now time.Time) {
+	if s.retention <= 0 {
+		return
+	}
+	s.mu.Lock()
+	if now.Sub(s.lastPurged) < auditPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurged = now
+	s.mu.Unlock()
+
+	cutoff := auditKey(now.Add(-s.retention))
+	removed := 0
+	for {
+		var expired []string
+		err := s.kv.Scan(auditBucket, "", auditBatchSize, func(key string, _ []byte) error {
+			if key < cutoff {
+				expired = append(expired, key)
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Warn("failed to scan audit events", "error", err)
+			return
+		}
+		for _, key := range expired {
+			if err := s.kv.Delete(auditBucket, key); err != nil {
+				slog.Warn("failed to delete expired audit event", "key", key, "error", err)
+				return
+			}
+		}
+		removed += len(expired)
+		if len(expired) < auditBatchSize {
+			break
+		}
+	}
+	if removed > 0 {
+		slog.Info("purged expired audit events", "count", removed, "retention", s.retention)
+	}
+}
+
+// auditKey is the prefix of the keys of the events that happened at t
+func auditKey(t time.Time) string {
+	return t.UTC().Format(auditKeyTimeFormat)
+}
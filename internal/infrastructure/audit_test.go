@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+func TestAuditStoreList(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, kv := range testKVStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store := NewAuditStore(kv, 0)
+			// More events than a batch, an hour apart, alternating between two reviews
+			total := 2*auditBatchSize + 10
+			for i := range total {
+				event := model.NewAuditEvent(model.AuditEventClicked, "C1", []string{"1.0", "2.0"}[i%2], "U1", start.Add(time.Duration(i)*time.Hour))
+				if err := store.Append(event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			at := func(i int) time.Time { return start.Add(time.Duration(i) * time.Hour) }
+			tests := []struct {
+				name      string
+				query     model.AuditQuery
+				wantCount int
+				wantFirst time.Time
+			}{
+				{name: "all", query: model.AuditQuery{}, wantCount: total, wantFirst: at(0)},
+				{name: "from", query: model.AuditQuery{From: at(150)}, wantCount: total - 150, wantFirst: at(150)},
+				{name: "from and to", query: model.AuditQuery{From: at(150), To: at(160)}, wantCount: 10, wantFirst: at(150)},
+				{name: "limit", query: model.AuditQuery{From: at(50), Limit: 120}, wantCount: 120, wantFirst: at(50)},
+				{name: "review and limit", query: model.AuditQuery{ReviewID: model.ReviewID("C1", "2.0"), Limit: 3}, wantCount: 3, wantFirst: at(1)},
+			}
+			for _, tt := range tests {
+				events, err := store.List(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(events) != tt.wantCount {
+					t.Errorf("%s: List() returned %d events, want %d", tt.name, len(events), tt.wantCount)
+					continue
+				}
+				if !events[0].At.Equal(tt.wantFirst) {
+					t.Errorf("%s: first event at %s, want %s", tt.name, events[0].At, tt.wantFirst)
+				}
+				for i := 1; i < len(events); i++ {
+					if events[i].At.Before(events[i-1].At) {
+						t.Errorf("%s: events out of order at %d", tt.name, i)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAuditStorePurgesExpiredEvents(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := NewAuditStore(NewMemoryKVStore(), 24*time.Hour)
+	store.now = func() time.Time { return now }
+	// Keep the first appends from purging so that expired events can be recorded
+	store.lastPurged = now
+	for i := range auditBatchSize + 10 {
+		event := model.NewAuditEvent(model.AuditEventClicked, "C1", "1.0", "U1", now.Add(-48*time.Hour+time.Duration(i)*time.Second))
+		if err := store.Append(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := model.NewAuditEvent(model.AuditEventCompleted, "C1", "1.0", "U1", now.Add(-time.Hour))
+	if err := store.Append(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(auditPurgeInterval)
+	latest := model.NewAuditEvent(model.AuditEventCompleted, "C1", "1.0", "U1", now)
+	if err := store.Append(latest); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.List(model.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].ID != recent.ID || events[1].ID != latest.ID {
+		t.Errorf("List() after the purge = %d events, want only the two within the retention", len(events))
+	}
+}
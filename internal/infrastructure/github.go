@@ -0,0 +1,330 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	// gitHubFilesPerPage is the largest page of the files of a pull request GitHub returns
+	gitHubFilesPerPage = 100
+	// gitHubMaxFilePages stops listing files where GitHub does, at 3000 files
+	gitHubMaxFilePages = 30
+	// codeHostRequestTimeout bounds each request to the API of a code host
+	codeHostRequestTimeout = 5 * time.Second
+	// codeHostCallTimeout bounds a call made of several requests, such as listing the files of a pull request,
+	// so that a slow code host cannot hold up the handling of a mention for long
+	codeHostCallTimeout = 10 * time.Second
+)
+
+// GitHubOptions configures the GitHub API client
+type GitHubOptions struct {
+	// Token is a personal access token or an installation token; the client is disabled without it
+	Token string
+	// APIURL is the base URL of the REST API, https://api.github.com by default
+	APIURL string
+	// WebhookSecret signs the webhook deliveries; webhooks are rejected without it
+	WebhookSecret string
+}
+
+// GitHubClient calls the GitHub REST API
+type GitHubClient struct {
+	httpClient *http.Client
+	options    GitHubOptions
+}
+
+var _ repository.CodeHost = (*GitHubClient)(nil)
+
+func NewGitHubClient(options GitHubOptions) *GitHubClient {
+	if options.APIURL == "" {
+		options.APIURL = "https://api.github.com"
+	}
+	options.APIURL = strings.TrimSuffix(options.APIURL, "/")
+	return &GitHubClient{
+		httpClient: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: codeHostRequestTimeout},
+		options:    options,
+	}
+}
+
+func (c *GitHubClient) Host() model.CodeHost {
+	return model.CodeHostGitHub
+}
+
+func (c *GitHubClient) FindPullRequestRefs(text string) []model.PullRequestRef {
+	return model.FindGitHubPullRequestRefs(text)
+}
+
+// gitHubPullRequest is the part of a pull request returned by the API that the bot uses
+type gitHubPullRequest struct {
+	Title string `json:"title"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changed_files"`
+}
+
+type gitHubFile struct {
+	Filename string `json:"filename"`
+}
+
+func (c *GitHubClient) GetPullRequest(ctx context.Context, ref model.PullRequestRef) (*model.PullRequest, error) {
+	if c.options.Token == "" {
+		return nil, repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", ref.Owner, ref.Repo, ref.Number)
+	var pr gitHubPullRequest
+	if err := c.get(ctx, path, &pr); err != nil {
+		return nil, err
+	}
+	pullRequest := &model.PullRequest{
+		PullRequestRef: ref,
+		Title:          pr.Title,
+		Author:         pr.User.Login,
+		Base:           pr.Base.Ref,
+		Additions:      pr.Additions,
+		Deletions:      pr.Deletions,
+		ChangedFiles:   pr.ChangedFiles,
+	}
+	for page := 1; page <= gitHubMaxFilePages; page++ {
+		var files []gitHubFile
+		if err := c.get(ctx, fmt.Sprintf("%s/files?per_page=%d&page=%d", path, gitHubFilesPerPage, page), &files); err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			pullRequest.Paths = append(pullRequest.Paths, file.Filename)
+		}
+		if len(files) < gitHubFilesPerPage {
+			break
+		}
+	}
+	return pullRequest, nil
+}
+
+// gitHubContent is a file returned by the contents API
+type gitHubContent struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GetCodeOwners looks for the CODEOWNERS file where GitHub does and parses the first one found
+func (c *GitHubClient) GetCodeOwners(ctx context.Context, ref model.PullRequestRef, gitRef string) (*model.CodeOwners, error) {
+	if c.options.Token == "" {
+		return nil, repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	for _, filePath := range model.CodeOwnersPaths[model.CodeHostGitHub] {
+		path := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", ref.Owner, ref.Repo, filePath, url.QueryEscape(gitRef))
+		var content gitHubContent
+		err := c.get(ctx, path, &content)
+		var apiErr *gitHubAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if content.Encoding != "base64" {
+			return nil, fmt.Errorf("unexpected encoding of %s: %q", filePath, content.Encoding)
+		}
+		// The content is wrapped over several lines
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+		}
+		return model.ParseCodeOwners(string(decoded)), nil
+	}
+	return nil, nil
+}
+
+// RequestReviewer asks the login for a review of the pull request
+func (c *GitHubClient) RequestReviewer(ctx context.Context, ref model.PullRequestRef, login string) error {
+	return c.changeRequestedReviewers(ctx, http.MethodPost, ref, login)
+}
+
+// RemoveRequestedReviewer withdraws the request for a review of the pull request from the login
+func (c *GitHubClient) RemoveRequestedReviewer(ctx context.Context, ref model.PullRequestRef, login string) error {
+	return c.changeRequestedReviewers(ctx, http.MethodDelete, ref, login)
+}
+
+func (c *GitHubClient) changeRequestedReviewers(ctx context.Context, method string, ref model.PullRequestRef, login string) error {
+	if c.options.Token == "" {
+		return repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/requested_reviewers", ref.Owner, ref.Repo, ref.Number)
+	body := map[string][]string{"reviewers": {login}}
+	return c.call(ctx, method, path, body, nil)
+}
+
+// gitHubAPIError is an error answer of the API
+type gitHubAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *gitHubAPIError) Error() string {
+	return fmt.Sprintf("GitHub answered %s %s with %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// get calls the API and decodes the JSON it answers with into v
+func (c *GitHubClient) get(ctx context.Context, path string, v any) error {
+	return c.call(ctx, http.MethodGet, path, nil, v)
+}
+
+// call calls the API with the body encoded as JSON unless it is nil,
+// and decodes the JSON it answers with into v unless v is nil
+func (c *GitHubClient) call(ctx context.Context, method, path string, body, v any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.options.APIURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.options.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call GitHub: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &gitHubAPIError{Method: method, Path: path, StatusCode: res.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return nil
+}
+
+// VerifyWebhook compares the X-Hub-Signature-256 header with the HMAC-SHA256 of the body
+func (c *GitHubClient) VerifyWebhook(r *model.HTTPRequest) error {
+	if c.options.WebhookSecret == "" {
+		return repository.ErrCodeHostDisabled
+	}
+	signature, ok := strings.CutPrefix(http.Header(r.Headers).Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return errors.New("missing webhook signature")
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed webhook signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, []byte(c.options.WebhookSecret))
+	mac.Write(r.Body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errors.New("webhook signature does not match")
+	}
+	return nil
+}
+
+// gitHubWebhookPayload is the part of pull_request and pull_request_review payloads that the bot uses
+type gitHubWebhookPayload struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+	} `json:"pull_request"`
+	Review struct {
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"review"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+func (c *GitHubClient) ParseWebhook(r *model.HTTPRequest) (*model.PullRequestEvent, error) {
+	header := http.Header(r.Headers)
+	eventName := header.Get("X-GitHub-Event")
+	if eventName != "pull_request" && eventName != "pull_request_review" {
+		return nil, nil
+	}
+	var payload gitHubWebhookPayload
+	if err := json.Unmarshal(r.Body, &payload); err != nil {
+		return nil, fmt.Errorf("malformed %s payload: %w", eventName, err)
+	}
+	event := &model.PullRequestEvent{
+		DeliveryID: header.Get("X-GitHub-Delivery"),
+		PullRequestRef: model.PullRequestRef{
+			Host:   model.CodeHostGitHub,
+			Owner:  payload.Repository.Owner.Login,
+			Repo:   payload.Repository.Name,
+			Number: payload.PullRequest.Number,
+		},
+		Sender: payload.Sender.Login,
+		Link:   payload.PullRequest.HTMLURL,
+	}
+	switch {
+	case eventName == "pull_request_review" && payload.Action == "submitted":
+		// Review states are lowercase in webhooks, unlike in the REST API
+		switch strings.ToLower(payload.Review.State) {
+		case "approved":
+			event.Action = model.PullRequestActionApproved
+		case "changes_requested":
+			event.Action = model.PullRequestActionChangesRequested
+		default:
+			return nil, nil
+		}
+		event.Sender = payload.Review.User.Login
+		event.Link = payload.Review.HTMLURL
+	case eventName == "pull_request" && payload.Action == "closed":
+		event.Action = model.PullRequestActionClosed
+		if payload.PullRequest.Merged {
+			event.Action = model.PullRequestActionMerged
+		}
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// fakeGitHub is a local stand-in for the GitHub REST API serving one pull request that changes the given number of files
+func fakeGitHub(t *testing.T, files int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer github-token" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		var v any
+		switch r.URL.Path {
+		case "/repos/octo/bot/pulls/7":
+			v = map[string]any{
+				"title":         "Add reviews",
+				"user":          map[string]string{"login": "alice"},
+				"base":          map[string]string{"ref": "main"},
+				"additions":     10,
+				"deletions":     2,
+				"changed_files": files,
+			}
+		case "/repos/octo/bot/pulls/7/files":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			var names []gitHubFile
+			for i := (page - 1) * gitHubFilesPerPage; i < min(page*gitHubFilesPerPage, files); i++ {
+				names = append(names, gitHubFile{Filename: fmt.Sprintf("file%03d.go", i)})
+			}
+			v = names
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitHubClientFindPullRequestRefs(t *testing.T) {
+	client := NewGitHubClient(GitHubOptions{})
+	text := "please review <https://github.com/octo/bot/pull/7|#7> and https://github.com/octo/bot/pull/7 then https://github.com/octo/other/pull/8, not https://github.com/octo/bot/issues/9"
+	want := []model.PullRequestRef{
+		{Host: model.CodeHostGitHub, Owner: "octo", Repo: "bot", Number: 7},
+		{Host: model.CodeHostGitHub, Owner: "octo", Repo: "other", Number: 8},
+	}
+	if got := client.FindPullRequestRefs(text); !slices.Equal(got, want) {
+		t.Errorf("FindPullRequestRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubClientGetPullRequest(t *testing.T) {
+	server := fakeGitHub(t, gitHubFilesPerPage+1)
+	client := NewGitHubClient(GitHubOptions{Token: "github-token", APIURL: server.URL + "/"})
+	ref := model.PullRequestRef{Host: model.CodeHostGitHub, Owner: "octo", Repo: "bot", Number: 7}
+
+	pullRequest, err := client.GetPullRequest(context.Background(), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pullRequest.Title != "Add reviews" || pullRequest.Author != "alice" || pullRequest.Base != "main" ||
+		pullRequest.Additions != 10 || pullRequest.Deletions != 2 || pullRequest.ChangedFiles != gitHubFilesPerPage+1 {
+		t.Errorf("GetPullRequest() = %+v", pullRequest)
+	}
+	// The files are listed across pages
+	if len(pullRequest.Paths) != gitHubFilesPerPage+1 || pullRequest.Paths[gitHubFilesPerPage] != fmt.Sprintf("file%03d.go", gitHubFilesPerPage) {
+		t.Errorf("paths = %d, last %v", len(pullRequest.Paths), pullRequest.Paths[len(pullRequest.Paths)-1:])
+	}
+
+	var apiErr *gitHubAPIError
+	missing := ref
+	missing.Number = 8
+	if _, err := client.GetPullRequest(context.Background(), missing); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetPullRequest() of a missing pull request error = %v, want a 404", err)
+	}
+}
+
+func TestGitHubClientDisabledWithoutToken(t *testing.T) {
+	server := fakeGitHub(t, 1)
+	client := NewGitHubClient(GitHubOptions{APIURL: server.URL})
+	ref := model.PullRequestRef{Host: model.CodeHostGitHub, Owner: "octo", Repo: "bot", Number: 7}
+	ctx := context.Background()
+
+	if _, err := client.GetPullRequest(ctx, ref); !errors.Is(err, repository.ErrCodeHostDisabled) {
+		t.Errorf("GetPullRequest() error = %v, want ErrCodeHostDisabled", err)
+	}
+	if _, err := client.GetCodeOwners(ctx, ref, "main"); !errors.Is(err, repository.ErrCodeHostDisabled) {
+		t.Errorf("GetCodeOwners() error = %v, want ErrCodeHostDisabled", err)
+	}
+	if err := client.RequestReviewer(ctx, ref, "bob"); !errors.Is(err, repository.ErrCodeHostDisabled) {
+		t.Errorf("RequestReviewer() error = %v, want ErrCodeHostDisabled", err)
+	}
+}
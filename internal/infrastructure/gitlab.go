@@ -0,0 +1,325 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	// gitLabDiffsPerPage is the largest page of the diffs of a merge request GitLab returns
+	gitLabDiffsPerPage = 100
+	// gitLabMaxDiffPages stops listing diffs at as many files as GitHub lists
+	gitLabMaxDiffPages = 30
+)
+
+// GitLabOptions configures the GitLab API client
+type GitLabOptions struct {
+	// Token is a personal, group or project access token with the api scope; the client is disabled without it
+	Token string
+	// URL is where the GitLab instance is served, https://gitlab.com by default
+	URL string
+	// WebhookToken is the secret token of the webhooks; webhooks are rejected without it
+	WebhookToken string
+}
+
+// GitLabClient calls the GitLab REST API
+type GitLabClient struct {
+	httpClient *http.Client
+	instance   *model.GitLabInstance
+	options    GitLabOptions
+}
+
+var _ repository.CodeHost = (*GitLabClient)(nil)
+
+func NewGitLabClient(options GitLabOptions) *GitLabClient {
+	if options.URL == "" {
+		options.URL = "https://gitlab.com"
+	}
+	instance := model.NewGitLabInstance(options.URL)
+	options.URL = instance.BaseURL
+	return &GitLabClient{
+		httpClient: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: codeHostRequestTimeout},
+		instance:   instance,
+		options:    options,
+	}
+}
+
+func (c *GitLabClient) Host() model.CodeHost {
+	return model.CodeHostGitLab
+}
+
+func (c *GitLabClient) FindPullRequestRefs(text string) []model.PullRequestRef {
+	return c.instance.FindMergeRequestRefs(text)
+}
+
+// gitLabUser is the part of a user returned by the API that the bot uses
+type gitLabUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// gitLabMergeRequest is the part of a merge request returned by the API that the bot uses
+type gitLabMergeRequest struct {
+	Title        string       `json:"title"`
+	Author       gitLabUser   `json:"author"`
+	TargetBranch string       `json:"target_branch"`
+	Reviewers    []gitLabUser `json:"reviewers"`
+}
+
+type gitLabDiff struct {
+	NewPath string `json:"new_path"`
+	Diff    string `json:"diff"`
+}
+
+// mergeRequestPath returns the API path of the merge request, below which its parts are found
+func mergeRequestPath(ref model.PullRequestRef) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(ref.ProjectPath()), ref.Number)
+}
+
+func (c *GitLabClient) GetPullRequest(ctx context.Context, ref model.PullRequestRef) (*model.PullRequest, error) {
+	if c.options.Token == "" {
+		return nil, repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	path := mergeRequestPath(ref)
+	var mr gitLabMergeRequest
+	if err := c.call(ctx, http.MethodGet, path, nil, &mr); err != nil {
+		return nil, err
+	}
+	pullRequest := &model.PullRequest{
+		PullRequestRef: ref,
+		Title:          mr.Title,
+		Author:         mr.Author.Username,
+		Base:           mr.TargetBranch,
+	}
+	// Unlike GitHub, GitLab does not count the changed lines, so they are counted in the diffs
+	for page := 1; page <= gitLabMaxDiffPages; page++ {
+		var diffs []gitLabDiff
+		if err := c.call(ctx, http.MethodGet, fmt.Sprintf("%s/diffs?per_page=%d&page=%d", path, gitLabDiffsPerPage, page), nil, &diffs); err != nil {
+			return nil, err
+		}
+		for _, diff := range diffs {
+			pullRequest.Paths = append(pullRequest.Paths, diff.NewPath)
+			for line := range strings.Lines(diff.Diff) {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				case strings.HasPrefix(line, "+"):
+					pullRequest.Additions++
+				case strings.HasPrefix(line, "-"):
+					pullRequest.Deletions++
+				}
+			}
+		}
+		if len(diffs) < gitLabDiffsPerPage {
+			break
+		}
+	}
+	pullRequest.ChangedFiles = len(pullRequest.Paths)
+	return pullRequest, nil
+}
+
+// GetCodeOwners looks for the CODEOWNERS file where GitLab does and parses the first one found
+func (c *GitLabClient) GetCodeOwners(ctx context.Context, ref model.PullRequestRef, gitRef string) (*model.CodeOwners, error) {
+	if c.options.Token == "" {
+		return nil, repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	for _, filePath := range model.CodeOwnersPaths[model.CodeHostGitLab] {
+		path := fmt.Sprintf("/projects/%s/repository/files/%s/raw?ref=%s", url.PathEscape(ref.ProjectPath()), url.PathEscape(filePath), url.QueryEscape(gitRef))
+		var content bytes.Buffer
+		err := c.call(ctx, http.MethodGet, path, nil, &content)
+		var apiErr *gitLabAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return model.ParseCodeOwners(content.String()), nil
+	}
+	return nil, nil
+}
+
+// RequestReviewer adds the user with the username to the reviewers of the merge request
+func (c *GitLabClient) RequestReviewer(ctx context.Context, ref model.PullRequestRef, login string) error {
+	return c.changeReviewers(ctx, ref, login, func(reviewerIDs []int, userID int) []int {
+		if slices.Contains(reviewerIDs, userID) {
+			return nil
+		}
+		return append(reviewerIDs, userID)
+	})
+}
+
+// RemoveRequestedReviewer removes the user with the username from the reviewers of the merge request
+func (c *GitLabClient) RemoveRequestedReviewer(ctx context.Context, ref model.PullRequestRef, login string) error {
+	return c.changeReviewers(ctx, ref, login, func(reviewerIDs []int, userID int) []int {
+		if !slices.Contains(reviewerIDs, userID) {
+			return nil
+		}
+		// Removing the last reviewer leaves an empty list rather than nil, which would mean no change
+		return slices.DeleteFunc(reviewerIDs, func(id int) bool { return id == userID })
+	})
+}
+
+// changeReviewers replaces the reviewers of the merge request with those change returns for the user,
+// leaving them as they are if it returns nil. GitLab can only set the reviewers as a whole.
+func (c *GitLabClient) changeReviewers(ctx context.Context, ref model.PullRequestRef, login string, change func(reviewerIDs []int, userID int) []int) error {
+	if c.options.Token == "" {
+		return repository.ErrCodeHostDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeHostCallTimeout)
+	defer cancel()
+	var users []gitLabUser
+	if err := c.call(ctx, http.MethodGet, "/users?username="+url.QueryEscape(login), nil, &users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("GitLab user %s not found", login)
+	}
+	path := mergeRequestPath(ref)
+	var mr gitLabMergeRequest
+	if err := c.call(ctx, http.MethodGet, path, nil, &mr); err != nil {
+		return err
+	}
+	reviewerIDs := make([]int, 0, len(mr.Reviewers))
+	for _, reviewer := range mr.Reviewers {
+		reviewerIDs = append(reviewerIDs, reviewer.ID)
+	}
+	changed := change(reviewerIDs, users[0].ID)
+	if changed == nil {
+		return nil
+	}
+	return c.call(ctx, http.MethodPut, path, map[string][]int{"reviewer_ids": changed}, nil)
+}
+
+// gitLabAPIError is an error answer of the API
+type gitLabAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *gitLabAPIError) Error() string {
+	return fmt.Sprintf("GitLab answered %s %s with %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// call calls the API with the body encoded as JSON unless it is nil. The answer is copied into v if it is
+// a bytes.Buffer, decoded from JSON into v otherwise, and discarded if v is nil.
+func (c *GitLabClient) call(ctx context.Context, method, path string, body, v any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.options.URL+"/api/v4"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.options.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call GitLab: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &gitLabAPIError{Method: method, Path: path, StatusCode: res.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err := v.ReadFrom(res.Body)
+		return err
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+	return nil
+}
+
+// VerifyWebhook compares the X-Gitlab-Token header with the secret token of the webhooks
+func (c *GitLabClient) VerifyWebhook(r *model.HTTPRequest) error {
+	if c.options.WebhookToken == "" {
+		return repository.ErrCodeHostDisabled
+	}
+	token := http.Header(r.Headers).Get("X-Gitlab-Token")
+	if token == "" {
+		return errors.New("missing webhook token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.options.WebhookToken)) != 1 {
+		return errors.New("webhook token does not match")
+	}
+	return nil
+}
+
+// gitLabWebhookPayload is the part of merge request events that the bot uses
+type gitLabWebhookPayload struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID    int    `json:"iid"`
+		URL    string `json:"url"`
+		Action string `json:"action"`
+	} `json:"object_attributes"`
+}
+
+func (c *GitLabClient) ParseWebhook(r *model.HTTPRequest) (*model.PullRequestEvent, error) {
+	header := http.Header(r.Headers)
+	if header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+		return nil, nil
+	}
+	var payload gitLabWebhookPayload
+	if err := json.Unmarshal(r.Body, &payload); err != nil {
+		return nil, fmt.Errorf("malformed merge request payload: %w", err)
+	}
+	event := &model.PullRequestEvent{
+		DeliveryID:     header.Get("X-Gitlab-Event-UUID"),
+		PullRequestRef: c.instance.MergeRequestRef(payload.Project.PathWithNamespace, payload.ObjectAttributes.IID),
+		Sender:         payload.User.Username,
+		Link:           payload.ObjectAttributes.URL,
+	}
+	switch payload.ObjectAttributes.Action {
+	// approval is sent for every approval, and approved once the merge request is approved as required
+	case "approval", "approved":
+		event.Action = model.PullRequestActionApproved
+	case "merge":
+		event.Action = model.PullRequestActionMerged
+	case "close":
+		event.Action = model.PullRequestActionClosed
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
+
+// NewCodeHosts lists the code hosts pull requests are looked for on
+func NewCodeHosts(github *GitHubClient, gitlab *GitLabClient) repository.CodeHosts {
+	return repository.CodeHosts{github, gitlab}
+}
@@ -0,0 +1,94 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+const idempotencyBucket = "idempotency_keys"
+
+// idempotencyPurgeInterval is how often expired keys are removed from the store
+const idempotencyPurgeInterval = 10 * time.Minute
+
+type idempotencyRecord struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IdempotencyStore is an IdempotencyRepository backed by a KVStore
+type IdempotencyStore struct {
+	kv  KVStore
+	ttl time.Duration
+	now func() time.Time
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+var _ repository.IdempotencyRepository = (*IdempotencyStore)(nil)
+
+func NewIdempotencyStore(kv KVStore, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		kv:         kv,
+		ttl:        ttl,
+		now:        time.Now,
+		lastPurged: time.Now(),
+	}
+}
+
+func (s *IdempotencyStore) Claim(key string) (bool, error) {
+	now := s.now()
+	s.purgeExpired(now)
+	claimed := false
+	err := s.kv.Update(idempotencyBucket, key, func(current []byte) ([]byte, error) {
+		if current != nil {
+			var record idempotencyRecord
+			if err := json.Unmarshal(current, &record); err == nil && now.Before(record.ExpiresAt) {
+				// Keep the existing record
+				return current, nil
+			}
+		}
+		claimed = true
+		return json.Marshal(idempotencyRecord{ExpiresAt: now.Add(s.ttl)})
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (s *IdempotencyStore) Release(key string) error {
+	return s.kv.Delete(idempotencyBucket, key)
+}
+
+// purgeExpired removes expired keys at most once per purge interval
+func (s *IdempotencyStore) purgeExpired(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPurged) < idempotencyPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurged = now
+	s.mu.Unlock()
+
+	var expired []string
+	err := s.kv.ForEach(idempotencyBucket, func(key string, value []byte) error {
+		var record idempotencyRecord
+		if err := json.Unmarshal(value, &record); err != nil || !now.Before(record.ExpiresAt) {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("failed to scan idempotency keys", "error", err)
+		return
+	}
+	for _, key := range expired {
+		if err := s.kv.Delete(idempotencyBucket, key); err != nil {
+			slog.Warn("failed to delete expired idempotency key", "key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedClient decorates a Slack repository with metrics and trace spans on received events and Web API calls
+type InstrumentedClient struct {
+	next    repository.SlackRepository
+	metrics *Metrics
+}
+
+var _ repository.SlackRepository = (*InstrumentedClient)(nil)
+
+func NewInstrumentedClient(client *ResilientClient, metrics *Metrics) *InstrumentedClient {
+	return &InstrumentedClient{
+		next:    client,
+		metrics: metrics,
+	}
+}
+
+func (c *InstrumentedClient) VerifyRequest(ctx context.Context, r *model.HTTPRequest) error {
+	ctx, span := tracer.Start(ctx, "slack.VerifyRequest")
+	defer span.End()
+	err := c.next.VerifyRequest(ctx, r)
+	recordError(span, err)
+	return err
+}
+
+func (c *InstrumentedClient) ParseEvent(ctx context.Context, body []byte) (model.Event, error) {
+	ctx, span := tracer.Start(ctx, "slack.ParseEvent")
+	defer span.End()
+	event, err := c.next.ParseEvent(ctx, body)
+	recordError(span, err)
+	if err == nil && event != nil {
+		span.SetAttributes(attribute.String("slack.event_type", eventType(event)))
+		c.metrics.events.WithLabelValues(eventType(event)).Inc()
+	}
+	return event, err
+}
+
+func (c *InstrumentedClient) ParseInteraction(ctx context.Context, body []byte) (model.Event, error) {
+	ctx, span := tracer.Start(ctx, "slack.ParseInteraction")
+	defer span.End()
+	event, err := c.next.ParseInteraction(ctx, body)
+	recordError(span, err)
+	if err == nil && event != nil {
+		span.SetAttributes(attribute.String("slack.event_type", eventType(event)))
+		c.metrics.events.WithLabelValues(eventType(event)).Inc()
+		if interaction, ok := event.(*model.InteractiveMessageEvent); ok {
+			span.SetAttributes(attribute.String("slack.action_id", interaction.ActionID))
+			c.metrics.interactions.WithLabelValues(interaction.ActionID).Inc()
+		}
+	}
+	return event, err
+}
+
+func (c *InstrumentedClient) ParseCommand(ctx context.Context, body []byte) (model.Event, error) {
+	ctx, span := tracer.Start(ctx, "slack.ParseCommand")
+	defer span.End()
+	event, err := c.next.ParseCommand(ctx, body)
+	recordError(span, err)
+	if err == nil && event != nil {
+		span.SetAttributes(attribute.String("slack.event_type", eventType(event)))
+		c.metrics.events.WithLabelValues(eventType(event)).Inc()
+	}
+	return event, err
+}
+
+func (c *InstrumentedClient) PostMessage(ctx context.Context, message *model.Message) (string, error) {
+	var timestamp string
+	err := c.observe(ctx, "chat.postMessage", func(ctx context.Context) error {
+		var err error
+		timestamp, err = c.next.PostMessage(ctx, message)
+		return err
+	})
+	return timestamp, err
+}
+
+func (c *InstrumentedClient) ReplaceMessage(ctx context.Context, message *model.Message, timestamp, responseURL string) error {
+	return c.observe(ctx, "chat.update", func(ctx context.Context) error {
+		return c.next.ReplaceMessage(ctx, message, timestamp, responseURL)
+	})
+}
+
+func (c *InstrumentedClient) GetThreadText(ctx context.Context, channelID, threadTS string) (string, error) {
+	var text string
+	err := c.observe(ctx, "conversations.replies", func(ctx context.Context) error {
+		var err error
+		text, err = c.next.GetThreadText(ctx, channelID, threadTS)
+		return err
+	})
+	return text, err
+}
+
+func (c *InstrumentedClient) FilterOnlineMemberIDs(ctx context.Context, memberIDs []model.MemberID) ([]model.MemberID, error) {
+	var onlineMemberIDs []model.MemberID
+	err := c.observe(ctx, "users.getPresence", func(ctx context.Context) error {
+		var err error
+		onlineMemberIDs, err = c.next.FilterOnlineMemberIDs(ctx, memberIDs)
+		return err
+	})
+	return onlineMemberIDs, err
+}
+
+func (c *InstrumentedClient) TestAuth(ctx context.Context) error {
+	return c.observe(ctx, "auth.test", func(ctx context.Context) error {
+		return c.next.TestAuth(ctx)
+	})
+}
+
+func (c *InstrumentedClient) GetUserLocale(ctx context.Context, memberID model.MemberID) (string, error) {
+	var locale string
+	err := c.observe(ctx, "users.info", func(ctx context.Context) error {
+		var err error
+		locale, err = c.next.GetUserLocale(ctx, memberID)
+		return err
+	})
+	return locale, err
+}
+
+// GetWorkspaceURL is not observed as a call because it is usually answered from the result of the last auth.test
+func (c *InstrumentedClient) GetWorkspaceURL(ctx context.Context) (string, error) {
+	return c.next.GetWorkspaceURL(ctx)
+}
+
+// observe records the call, its latency and its failure under the Web API method
+func (c *InstrumentedClient) observe(ctx context.Context, method string, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(
+		ctx,
+		"slack "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("slack.method", method)),
+	)
+	defer span.End()
+	start := time.Now()
+	err := fn(ctx)
+	c.metrics.slackAPIDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	c.metrics.slackAPICalls.WithLabelValues(method).Inc()
+	if err != nil {
+		c.metrics.slackAPIErrors.WithLabelValues(method).Inc()
+	}
+	recordError(span, err)
+	return err
+}
+
+// eventType returns the label of the event in the events metric
+func eventType(event model.Event) string {
+	switch event.(type) {
+	case *model.AppMentionEvent:
+		return "app_mention"
+	case *model.InteractiveMessageEvent:
+		return "interactive_message"
+	case *model.URLVerificationEvent:
+		return "url_verification"
+	case *model.ReactionAddedEvent:
+		return "reaction_added"
+	case *model.SlashCommandEvent:
+		return "slash_command"
+	default:
+		return "unknown"
+	}
+}
+
+// recordError marks the span as failed when err is not nil
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
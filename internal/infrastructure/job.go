@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+const jobBucket = "jobs"
+
+// JobStore is a JobRepository backed by a KVStore
+type JobStore struct {
+	kv KVStore
+}
+
+var _ repository.JobRepository = (*JobStore)(nil)
+
+func NewJobStore(kv KVStore) *JobStore {
+	return &JobStore{
+		kv: kv,
+	}
+}
+
+func (s *JobStore) Save(job *model.Job) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.kv.Put(jobBucket, job.ID, b)
+}
+
+func (s *JobStore) Delete(id string) error {
+	return s.kv.Delete(jobBucket, id)
+}
+
+func (s *JobStore) List() ([]*model.Job, error) {
+	var jobs []*model.Job
+	err := s.kv.ForEach(jobBucket, func(_ string, value []byte) error {
+		var job model.Job
+		if err := json.Unmarshal(value, &job); err != nil {
+			return err
+		}
+		jobs = append(jobs, &job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+	})
+	return jobs, nil
+}
@@ -0,0 +1,321 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrJobQueueFull is returned when the queue has no room for another job
+	ErrJobQueueFull = errors.New("job queue is full")
+	// ErrJobQueueClosed is returned when a job is enqueued after the queue has been shut down
+	ErrJobQueueClosed = errors.New("job queue is closed")
+)
+
+// WorkerPoolOptions configures a WorkerPool
+type WorkerPoolOptions struct {
+	// Workers is the number of jobs processed concurrently
+	Workers int
+	// QueueSize is the number of jobs that can wait for a worker
+	QueueSize int
+	// JobTimeout bounds a single attempt of a job
+	JobTimeout time.Duration
+	// MaxAttempts is the number of attempts before a failing job is dropped
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry, doubled on every further retry
+	RetryDelay time.Duration
+}
+
+// WorkerPool is a bounded JobQueue processed by a fixed number of workers
+type WorkerPool struct {
+	repo    repository.JobRepository
+	options WorkerPoolOptions
+
+	mu       sync.RWMutex
+	handlers map[string]model.JobHandler
+	closed   bool
+
+	jobs chan *model.Job
+	quit chan struct{}
+	// workers tracks running workers and the scheduler, pending tracks jobs that are queued, running or waiting for a retry
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+
+	// delayed are the jobs waiting to be queued again, which the scheduler is woken up for through wake
+	delayedMu sync.Mutex
+	delayed   []delayedJob
+	wake      chan struct{}
+}
+
+// delayedJob is a job to be queued again at a time
+type delayedJob struct {
+	job *model.Job
+	at  time.Time
+}
+
+var _ repository.JobQueue = (*WorkerPool)(nil)
+
+func NewWorkerPool(repo repository.JobRepository, options WorkerPoolOptions) *WorkerPool {
+	return &WorkerPool{
+		repo:     repo,
+		options:  options,
+		handlers: make(map[string]model.JobHandler),
+		jobs:     make(chan *model.Job, options.QueueSize),
+		quit:     make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (p *WorkerPool) Register(jobType string, handler model.JobHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[jobType] = handler
+}
+
+func (p *WorkerPool) Enqueue(ctx context.Context, jobType string, payload []byte) error {
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+	job := &model.Job{
+		ID:           newID(),
+		Type:         jobType,
+		Payload:      payload,
+		MaxAttempts:  p.options.MaxAttempts,
+		EnqueuedAt:   time.Now(),
+		TraceContext: traceContext,
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrJobQueueClosed
+	}
+	if err := p.repo.Save(job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	p.pending.Add(1)
+	select {
+	case p.jobs <- job:
+		slog.Info("job enqueued", "job_id", job.ID, "job_type", job.Type)
+		return nil
+	default:
+		p.pending.Done()
+		if err := p.repo.Delete(job.ID); err != nil {
+			slog.Error("failed to delete rejected job", "job_id", job.ID, "error", err)
+		}
+		return ErrJobQueueFull
+	}
+}
+
+// Start starts the workers and requeues the jobs left over from a previous run
+func (p *WorkerPool) Start() error {
+	leftover, err := p.repo.List()
+	if err != nil {
+		return fmt.Errorf("failed to list persisted jobs: %w", err)
+	}
+	for i := 0; i < p.options.Workers; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	p.workers.Add(1)
+	go p.schedule()
+	if len(leftover) > 0 {
+		slog.Info("requeueing persisted jobs", "count", len(leftover))
+		p.pending.Add(len(leftover))
+		// The leftover jobs may not fit into the queue at once, so the scheduler queues them as room frees up
+		now := time.Now()
+		for _, job := range leftover {
+			p.delay(job, now)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops accepting jobs and waits for queued, running and retrying jobs to finish.
+// If ctx expires first, the unfinished jobs stay in the repository and run after the next start.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		close(p.quit)
+		p.workers.Wait()
+		slog.Info("job queue drained")
+		return nil
+	case <-ctx.Done():
+		close(p.quit)
+		return fmt.Errorf("job queue not drained: %w", ctx.Err())
+	}
+}
+
+func (p *WorkerPool) work() {
+	defer p.workers.Done()
+	for {
+		select {
+		case job := <-p.jobs:
+			p.run(job)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// run performs one attempt of the job and schedules a retry when it fails
+func (p *WorkerPool) run(job *model.Job) {
+	p.mu.RLock()
+	handler, ok := p.handlers[job.Type]
+	p.mu.RUnlock()
+	if !ok {
+		slog.Error("no handler registered for job type", "job_id", job.ID, "job_type", job.Type)
+		p.finish(job)
+		return
+	}
+	job.Attempts++
+	if err := p.repo.Save(job); err != nil {
+		slog.Warn("failed to save job attempt", "job_id", job.ID, "error", err)
+	}
+	start := time.Now()
+	err := p.invoke(handler, job)
+	if err == nil {
+		slog.Info("job completed", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "duration", time.Since(start))
+		p.finish(job)
+		return
+	}
+	if job.IsLastAttempt() {
+		slog.Error("job failed permanently", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "error", err)
+		p.finish(job)
+		return
+	}
+	delay := p.options.RetryDelay << (job.Attempts - 1)
+	slog.Warn("job failed, retrying", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "delay", delay, "error", err)
+	p.delay(job, time.Now().Add(delay))
+}
+
+// delay has the scheduler queue the job again at the time
+func (p *WorkerPool) delay(job *model.Job, at time.Time) {
+	p.delayedMu.Lock()
+	p.delayed = append(p.delayed, delayedJob{job: job, at: at})
+	p.delayedMu.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+		// The scheduler is already due to look at the delayed jobs
+	}
+}
+
+// nextDelayed removes and returns the delayed job that is due first if it is due by now.
+// Otherwise it returns how long until the first one is due, or false if there is none.
+func (p *WorkerPool) nextDelayed(now time.Time) (*model.Job, time.Duration, bool) {
+	p.delayedMu.Lock()
+	defer p.delayedMu.Unlock()
+	if len(p.delayed) == 0 {
+		return nil, 0, false
+	}
+	first := 0
+	for i, d := range p.delayed {
+		if d.at.Before(p.delayed[first].at) {
+			first = i
+		}
+	}
+	if wait := p.delayed[first].at.Sub(now); wait > 0 {
+		return nil, wait, true
+	}
+	job := p.delayed[first].job
+	p.delayed = append(p.delayed[:first], p.delayed[first+1:]...)
+	return job, 0, true
+}
+
+// schedule queues the delayed jobs as they become due. Waiting for room in the queue holds back only
+// this one goroutine, which stops on shutdown; jobs it has not queued by then stay in the repository.
+func (p *WorkerPool) schedule() {
+	defer p.workers.Done()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		job, wait, ok := p.nextDelayed(time.Now())
+		if job != nil {
+			select {
+			case p.jobs <- job:
+				continue
+			case <-p.quit:
+				return
+			}
+		}
+		timer.Stop()
+		var due <-chan time.Time
+		if ok {
+			timer.Reset(wait)
+			due = timer.C
+		}
+		select {
+		case <-due:
+		case <-p.wake:
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// invoke calls the handler with a per-attempt timeout, converting a panic into an error.
+// Each attempt gets its own trace, linked to the span of the request that enqueued the job.
+func (p *WorkerPool) invoke(handler model.JobHandler, job *model.Job) (err error) {
+	origin := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(job.TraceContext))
+	ctx, span := tracer.Start(
+		context.Background(),
+		"job "+job.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.LinkFromContext(origin)),
+		trace.WithAttributes(
+			attribute.String("job.id", job.ID),
+			attribute.String("job.type", job.Type),
+			attribute.Int("job.attempt", job.Attempts),
+		),
+	)
+	defer span.End()
+	ctx = logging.With(ctx, slog.String("job_id", job.ID), slog.String("job_type", job.Type))
+	ctx, cancel := context.WithTimeout(ctx, p.options.JobTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "job panicked", "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+		recordError(span, err)
+	}()
+	return handler(ctx, job)
+}
+
+// finish removes a job that will not run again
+func (p *WorkerPool) finish(job *model.Job) {
+	if err := p.repo.Delete(job.ID); err != nil {
+		slog.Error("failed to delete finished job", "job_id", job.ID, "error", err)
+	}
+	p.pending.Done()
+}
+
+// newID returns a random hex ID
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
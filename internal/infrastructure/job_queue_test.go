@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+func newTestWorkerPool(t *testing.T, options WorkerPoolOptions) (*WorkerPool, *JobStore) {
+	t.Helper()
+	repo := NewJobStore(NewMemoryKVStore())
+	pool := NewWorkerPool(repo, options)
+	return pool, repo
+}
+
+// waitForWorkers fails the test unless the workers and the scheduler of the pool stop within a second
+func waitForWorkers(t *testing.T, pool *WorkerPool) {
+	t.Helper()
+	stopped := make(chan struct{})
+	go func() {
+		pool.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("workers did not stop")
+	}
+}
+
+func TestWorkerPoolRetriesFailedJobs(t *testing.T) {
+	pool, repo := newTestWorkerPool(t, WorkerPoolOptions{Workers: 1, QueueSize: 1, JobTimeout: time.Second, MaxAttempts: 3, RetryDelay: time.Millisecond})
+	var attempts atomic.Int32
+	done := make(chan struct{})
+	pool.Register("flaky", func(context.Context, *model.Job) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("transient failure")
+		}
+		close(done)
+		return nil
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Enqueue(context.Background(), "flaky", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("job did not succeed after %d attempts", attempts.Load())
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if jobs, _ := repo.List(); len(jobs) != 0 {
+		t.Errorf("jobs left in the repository = %d, want 0", len(jobs))
+	}
+}
+
+func TestWorkerPoolShutdownStopsWaitingRetries(t *testing.T) {
+	pool, repo := newTestWorkerPool(t, WorkerPoolOptions{Workers: 1, QueueSize: 1, JobTimeout: time.Second, MaxAttempts: 5, RetryDelay: time.Hour})
+	failed := make(chan struct{}, 1)
+	pool.Register("failing", func(context.Context, *model.Job) error {
+		failed <- struct{}{}
+		return errors.New("permanent failure")
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Enqueue(context.Background(), "failing", nil); err != nil {
+		t.Fatal(err)
+	}
+	<-failed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err == nil {
+		t.Fatal("Shutdown() drained the queue while a retry was waiting")
+	}
+	waitForWorkers(t, pool)
+	if jobs, _ := repo.List(); len(jobs) != 1 {
+		t.Errorf("jobs left in the repository = %d, want the waiting retry to run after the next start", len(jobs))
+	}
+}
+
+func TestWorkerPoolRequeuesMoreLeftoverJobsThanFit(t *testing.T) {
+	repo := NewJobStore(NewMemoryKVStore())
+	for i := range 3 {
+		if err := repo.Save(&model.Job{ID: string(rune('a' + i)), Type: "leftover", MaxAttempts: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool := NewWorkerPool(repo, WorkerPoolOptions{Workers: 1, QueueSize: 1, JobTimeout: time.Second, MaxAttempts: 1})
+	var ran atomic.Int32
+	pool.Register("leftover", func(context.Context, *model.Job) error {
+		ran.Add(1)
+		return nil
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := ran.Load(); got != 3 {
+		t.Errorf("jobs run = %d, want 3", got)
+	}
+}
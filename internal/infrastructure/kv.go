@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrKeyNotFound is returned when a key does not exist in a bucket
+var ErrKeyNotFound = errors.New("key not found")
+
+// KVStore is a bucketed key-value store backing the repositories
+type KVStore interface {
+	// Get returns the value stored under key, or ErrKeyNotFound
+	Get(bucket, key string) ([]byte, error)
+	// Put stores value under key
+	Put(bucket, key string, value []byte) error
+	// Update atomically replaces the value under key with the result of fn.
+	// fn receives nil when the key does not exist; returning a nil value deletes the key.
+	Update(bucket, key string, fn func(current []byte) ([]byte, error)) error
+	// Delete removes key; deleting a missing key is not an error
+	Delete(bucket, key string) error
+	// ForEach calls fn for every key in the bucket in ascending key order.
+	// fn must not modify the store; collect the keys and modify them afterwards instead.
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	// Scan calls fn for at most limit keys after the given one (from the first key when it is empty)
+	// in ascending key order, all read at once. fn must not modify the store.
+	Scan(bucket, after string, limit int, fn func(key string, value []byte) error) error
+	// Ping reports whether the store can be read
+	Ping() error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// MemoryKVStore is a KVStore that keeps everything in memory and loses it on restart
+type MemoryKVStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+var _ KVStore = (*MemoryKVStore)(nil)
+
+func NewMemoryKVStore() *MemoryKVStore {
+	return &MemoryKVStore{
+		buckets: make(map[string]map[string][]byte),
+	}
+}
+
+func (s *MemoryKVStore) Get(bucket, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.buckets[bucket][key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *MemoryKVStore) Put(bucket, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(bucket, key, value)
+	return nil
+}
+
+func (s *MemoryKVStore) Update(bucket, key string, fn func(current []byte) ([]byte, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var current []byte
+	if value, ok := s.buckets[bucket][key]; ok {
+		current = append([]byte(nil), value...)
+	}
+	next, err := fn(current)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		delete(s.buckets[bucket], key)
+		return nil
+	}
+	s.put(bucket, key, next)
+	return nil
+}
+
+func (s *MemoryKVStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets[bucket], key)
+	return nil
+}
+
+func (s *MemoryKVStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	values := make(map[string][]byte, len(s.buckets[bucket]))
+	for key, value := range s.buckets[bucket] {
+		keys = append(keys, key)
+		values[key] = append([]byte(nil), value...)
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryKVStore) Scan(bucket, after string, limit int, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		if key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	keys = keys[:min(limit, len(keys))]
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		values[i] = append([]byte(nil), s.buckets[bucket][key]...)
+	}
+	s.mu.RUnlock()
+	for i, key := range keys {
+		if err := fn(key, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryKVStore) Ping() error {
+	return nil
+}
+
+func (s *MemoryKVStore) Close() error {
+	return nil
+}
+
+func (s *MemoryKVStore) put(bucket, key string, value []byte) {
+	b, ok := s.buckets[bucket]
+	if !ok {
+		b = make(map[string][]byte)
+		s.buckets[bucket] = b
+	}
+	b[key] = append([]byte(nil), value...)
+}
@@ -0,0 +1,150 @@
+package infrastructure
+
+import (
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrStoreLocked is returned when another process, such as a running server, holds the database file
+var ErrStoreLocked = errors.New("store is held by another process")
+
+// BoltKVStore is a KVStore persisted to a single bbolt database file
+type BoltKVStore struct {
+	db *bolt.DB
+}
+
+var _ KVStore = (*BoltKVStore)(nil)
+
+func NewBoltKVStore(path string) (*BoltKVStore, error) {
+	return openBoltKVStore(path, false)
+}
+
+// NewReadOnlyBoltKVStore opens an existing database file for reading, e.g. for reports from the command line.
+// The file cannot be opened while a server holds it, which fails with ErrStoreLocked.
+func NewReadOnlyBoltKVStore(path string) (*BoltKVStore, error) {
+	return openBoltKVStore(path, true)
+}
+
+func openBoltKVStore(path string, readOnly bool) (*BoltKVStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{
+		// Fail instead of blocking forever when another process holds the file
+		Timeout:  5 * time.Second,
+		ReadOnly: readOnly,
+	})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, ErrStoreLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BoltKVStore{
+		db: db,
+	}, nil
+}
+
+func (s *BoltKVStore) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrKeyNotFound
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return ErrKeyNotFound
+		}
+		// Values are only valid during the transaction
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+func (s *BoltKVStore) Put(bucket, key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+func (s *BoltKVStore) Update(bucket, key string, fn func(current []byte) ([]byte, error)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		var current []byte
+		if v := b.Get([]byte(key)); v != nil {
+			current = append([]byte(nil), v...)
+		}
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return b.Delete([]byte(key))
+		}
+		return b.Put([]byte(key), next)
+	})
+}
+
+func (s *BoltKVStore) Delete(bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (s *BoltKVStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			// Values are only valid during the transaction
+			return fn(string(k), append([]byte(nil), v...))
+		})
+	})
+}
+
+func (s *BoltKVStore) Scan(bucket, after string, limit int, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		k, v := c.Seek([]byte(after))
+		if k != nil && string(k) == after {
+			k, v = c.Next()
+		}
+		for n := 0; k != nil && n < limit; n++ {
+			// Values are only valid during the transaction
+			if err := fn(string(k), append([]byte(nil), v...)); err != nil {
+				return err
+			}
+			k, v = c.Next()
+		}
+		return nil
+	})
+}
+
+func (s *BoltKVStore) Ping() error {
+	// Fails once the database has been closed
+	return s.db.View(func(*bolt.Tx) error {
+		return nil
+	})
+}
+
+func (s *BoltKVStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,108 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+func testKVStores(t *testing.T) map[string]KVStore {
+	t.Helper()
+	bolt, err := NewBoltKVStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = bolt.Close() })
+	return map[string]KVStore{"memory": NewMemoryKVStore(), "bolt": bolt}
+}
+
+func TestKVStoreScan(t *testing.T) {
+	for name, kv := range testKVStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"c", "a", "d", "b"} {
+				if err := kv.Put("bucket", key, []byte(key)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tests := []struct {
+				after string
+				limit int
+				want  []string
+			}{
+				{after: "", limit: 2, want: []string{"a", "b"}},
+				{after: "b", limit: 2, want: []string{"c", "d"}},
+				{after: "bb", limit: 5, want: []string{"c", "d"}},
+				{after: "d", limit: 2, want: nil},
+			}
+			for _, tt := range tests {
+				var got []string
+				err := kv.Scan("bucket", tt.after, tt.limit, func(key string, value []byte) error {
+					if string(value) != key {
+						t.Errorf("value of %s = %q", key, value)
+					}
+					got = append(got, key)
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("Scan(after %q, limit %d) = %v, want %v", tt.after, tt.limit, got, tt.want)
+				}
+			}
+			if err := kv.Scan("missing", "", 1, func(string, []byte) error {
+				t.Error("Scan() called fn for a missing bucket")
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReviewStoreEachReadsInBatches(t *testing.T) {
+	for name, kv := range testKVStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store := NewReviewStore(kv)
+			total := 2*reviewBatchSize + 1
+			for i := range total {
+				if err := store.Save(&model.Review{ID: fmt.Sprintf("review-%04d", i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var ids []string
+			err := store.Each(func(review *model.Review) error {
+				ids = append(ids, review.ID)
+				// Saving while iterating only works when fn runs outside of the reads
+				review.Status = model.ReviewStatusCompleted
+				return store.Save(review)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ids) != total || !slices.IsSorted(ids) {
+				t.Fatalf("Each() visited %d reviews, sorted %v, want %d in order", len(ids), slices.IsSorted(ids), total)
+			}
+			if review, err := store.Get(ids[total-1]); err != nil || review.Status != model.ReviewStatusCompleted {
+				t.Errorf("last review = %+v, %v, want it saved as completed", review, err)
+			}
+		})
+	}
+}
+
+func TestReadOnlyBoltKVStoreFailsWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	held, err := NewBoltKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	if _, err := NewReadOnlyBoltKVStore(path); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("NewReadOnlyBoltKVStore() while held = %v, want ErrStoreLocked", err)
+	}
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// localeCacheTTL is how long a member's locale is reused before it is looked up again; members rarely change it
+const localeCacheTTL = time.Hour
+
+type localeEntry struct {
+	locale    string
+	expiresAt time.Time
+}
+
+// LocaleCacheClient decorates a Slack repository with a cache of member locales,
+// so that localizing every message, including answers Slack waits for, does not look up the member again
+type LocaleCacheClient struct {
+	repository.SlackRepository
+	metrics *Metrics
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[model.MemberID]localeEntry
+}
+
+var _ repository.SlackRepository = (*LocaleCacheClient)(nil)
+
+func NewLocaleCacheClient(client *PresenceCacheClient, metrics *Metrics) *LocaleCacheClient {
+	return &LocaleCacheClient{
+		SlackRepository: client,
+		metrics:         metrics,
+		ttl:             localeCacheTTL,
+		entries:         make(map[model.MemberID]localeEntry),
+	}
+}
+
+func (c *LocaleCacheClient) GetUserLocale(ctx context.Context, memberID model.MemberID) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[memberID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		c.metrics.localeCache.WithLabelValues("hit").Inc()
+		return entry.locale, nil
+	}
+	c.metrics.localeCache.WithLabelValues("miss").Inc()
+	locale, err := c.SlackRepository.GetUserLocale(ctx, memberID)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[memberID] = localeEntry{locale: locale, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return locale, nil
+}
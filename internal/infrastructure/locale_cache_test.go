@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// fakeLocales answers locale lookups with the given locale, counting them
+type fakeLocales struct {
+	repository.SlackRepository
+	locale  string
+	err     error
+	lookups int
+}
+
+func (f *fakeLocales) GetUserLocale(context.Context, model.MemberID) (string, error) {
+	f.lookups++
+	return f.locale, f.err
+}
+
+func TestLocaleCacheClient(t *testing.T) {
+	next := &fakeLocales{err: errors.New("slack is down")}
+	client := &LocaleCacheClient{
+		SlackRepository: next,
+		metrics:         NewMetrics(NewReviewStore(NewMemoryKVStore())),
+		ttl:             time.Hour,
+		entries:         make(map[model.MemberID]localeEntry),
+	}
+	ctx := context.Background()
+
+	// Failed lookups are not cached
+	if _, err := client.GetUserLocale(ctx, "U1"); err == nil {
+		t.Fatal("GetUserLocale() succeeded while Slack failed")
+	}
+	next.locale, next.err = "en-US", nil
+	for range 3 {
+		if locale, err := client.GetUserLocale(ctx, "U1"); err != nil || locale != "en-US" {
+			t.Fatalf("GetUserLocale() = %q, %v, want en-US", locale, err)
+		}
+	}
+	if next.lookups != 2 {
+		t.Errorf("lookups = %d, want 2", next.lookups)
+	}
+	// Expired locales are looked up again
+	client.entries["U1"] = localeEntry{locale: "en-US", expiresAt: time.Now().Add(-time.Second)}
+	next.locale = "ja-JP"
+	if locale, _ := client.GetUserLocale(ctx, "U1"); locale != "ja-JP" {
+		t.Errorf("GetUserLocale() after expiry = %q, want ja-JP", locale)
+	}
+}
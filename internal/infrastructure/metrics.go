@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"net/http"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "review_bot"
+
+// Metrics holds the Prometheus collectors of the bot
+type Metrics struct {
+	registry *prometheus.Registry
+
+	events           *prometheus.CounterVec
+	interactions     *prometheus.CounterVec
+	assignments      *prometheus.CounterVec
+	slackAPICalls    *prometheus.CounterVec
+	slackAPIErrors   *prometheus.CounterVec
+	slackAPIDuration *prometheus.HistogramVec
+	presenceCache    *prometheus.CounterVec
+	localeCache      *prometheus.CounterVec
+	signingSecrets   *prometheus.CounterVec
+}
+
+func NewMetrics(reviewStore *ReviewStore) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_total",
+			Help:      "Slack events received, by event type.",
+		}, []string{"type"}),
+		interactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "interactions_total",
+			Help:      "Slack interactions received, by action ID.",
+		}, []string{"action_id"}),
+		assignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "assignments_total",
+			Help:      "Reviewers assigned, by assignment mode.",
+		}, []string{"mode"}),
+		slackAPICalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "slack_api_calls_total",
+			Help:      "Slack Web API calls, by method. Retries are part of a single call.",
+		}, []string{"method"}),
+		slackAPIErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "slack_api_errors_total",
+			Help:      "Slack Web API calls that failed, by method.",
+		}, []string{"method"}),
+		slackAPIDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "slack_api_call_duration_seconds",
+			Help:      "Latency of Slack Web API calls including retries, by method.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"method"}),
+		presenceCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "presence_cache_lookups_total",
+			Help:      "Presence lookups, by whether they were answered from the cache (hit) or by Slack (miss).",
+		}, []string{"result"}),
+		signingSecrets: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "signing_secret_verifications_total",
+			Help:      "Requests verified, by whether the primary or a previous signing secret matched.",
+		}, []string{"secret"}),
+		localeCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "locale_cache_lookups_total",
+			Help:      "Member locale lookups, by whether they were answered from the cache (hit) or by Slack (miss).",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.events,
+		m.interactions,
+		m.assignments,
+		m.slackAPICalls,
+		m.slackAPIErrors,
+		m.slackAPIDuration,
+		m.presenceCache,
+		m.localeCache,
+		m.signingSecrets,
+		newOpenReviewsCollector(reviewStore),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// openReviewsCollector reports the reviews currently assigned to each reviewer, counted in the store on every scrape
+// a batch of reviews at a time
+type openReviewsCollector struct {
+	reviewRepo repository.ReviewRepository
+	desc       *prometheus.Desc
+}
+
+func newOpenReviewsCollector(reviewRepo repository.ReviewRepository) *openReviewsCollector {
+	return &openReviewsCollector{
+		reviewRepo: reviewRepo,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "open_reviews"),
+			"Reviews assigned and not yet completed, by reviewer.",
+			[]string{"reviewer"},
+			nil,
+		),
+	}
+}
+
+func (c *openReviewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *openReviewsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[string]int)
+	err := c.reviewRepo.Each(func(review *model.Review) error {
+		if review.Status.IsInReview() {
+			counts[review.Reviewer.DisplayName]++
+		}
+		return nil
+	})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for reviewer, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), reviewer)
+	}
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// presenceCacheTTL is how long a member's presence is reused before it is looked up again
+const presenceCacheTTL = 30 * time.Second
+
+type presenceEntry struct {
+	online    bool
+	expiresAt time.Time
+}
+
+// PresenceCacheClient decorates a Slack repository with a short-lived cache of member presence,
+// so that urgent assignments in quick succession do not look up every reviewer again
+type PresenceCacheClient struct {
+	repository.SlackRepository
+	metrics *Metrics
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[model.MemberID]presenceEntry
+}
+
+var _ repository.SlackRepository = (*PresenceCacheClient)(nil)
+
+func NewPresenceCacheClient(client *InstrumentedClient, metrics *Metrics) *PresenceCacheClient {
+	return &PresenceCacheClient{
+		SlackRepository: client,
+		metrics:         metrics,
+		ttl:             presenceCacheTTL,
+		entries:         make(map[model.MemberID]presenceEntry),
+	}
+}
+
+func (c *PresenceCacheClient) FilterOnlineMemberIDs(ctx context.Context, memberIDs []model.MemberID) ([]model.MemberID, error) {
+	now := time.Now()
+	online := make(map[model.MemberID]bool, len(memberIDs))
+	var misses []model.MemberID
+	c.mu.Lock()
+	for _, memberID := range memberIDs {
+		entry, ok := c.entries[memberID]
+		if ok && now.Before(entry.expiresAt) {
+			online[memberID] = entry.online
+		} else {
+			misses = append(misses, memberID)
+		}
+	}
+	c.mu.Unlock()
+	c.metrics.presenceCache.WithLabelValues("hit").Add(float64(len(memberIDs) - len(misses)))
+	c.metrics.presenceCache.WithLabelValues("miss").Add(float64(len(misses)))
+
+	if len(misses) > 0 {
+		onlineMisses, err := c.SlackRepository.FilterOnlineMemberIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, memberID := range misses {
+			online[memberID] = false
+		}
+		for _, memberID := range onlineMisses {
+			online[memberID] = true
+		}
+		expiresAt := time.Now().Add(c.ttl)
+		c.mu.Lock()
+		for _, memberID := range misses {
+			c.entries[memberID] = presenceEntry{online: online[memberID], expiresAt: expiresAt}
+		}
+		c.mu.Unlock()
+	}
+
+	var onlineMemberIDs []model.MemberID
+	for _, memberID := range memberIDs {
+		if online[memberID] {
+			onlineMemberIDs = append(onlineMemberIDs, memberID)
+		}
+	}
+	return onlineMemberIDs, nil
+}
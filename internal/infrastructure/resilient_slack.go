@@ -0,0 +1,256 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/slack-go/slack"
+)
+
+// RetryPolicy configures how a Slack Web API method is retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// NotIdempotent methods are not retried after failures that Slack may have carried the call out despite,
+	// such as a timeout, since doing so would repeat it
+	NotIdempotent bool
+}
+
+// retryPolicies holds the retry policy of each Slack Web API method, sized to its rate limit tier.
+// See https://api.slack.com/apis/rate-limits
+var retryPolicies = map[string]RetryPolicy{
+	// Special tier: about one message per second per channel
+	"chat.postMessage": {MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second, NotIdempotent: true},
+	// Tier 3: 50+ requests per minute
+	"chat.update": {MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second},
+	// Tier 3: 50+ requests per minute
+	"conversations.replies": {MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second},
+	// Tier 3: 50+ requests per minute
+	"users.getPresence": {MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second},
+	// Tier 4: 100+ requests per minute
+	"users.info": {MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second},
+}
+
+// retryableSlackErrors are Slack error codes that indicate a transient failure on Slack's side
+var retryableSlackErrors = map[string]bool{
+	"ratelimited":         true,
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// ResilientClient decorates a Slack repository with retries, backoff and a circuit breaker around Web API calls
+type ResilientClient struct {
+	next     repository.SlackRepository
+	policies map[string]RetryPolicy
+	breaker  *circuitBreaker
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+var _ repository.SlackRepository = (*ResilientClient)(nil)
+
+func NewResilientClient(client *Client) *ResilientClient {
+	return &ResilientClient{
+		next:     client,
+		policies: retryPolicies,
+		breaker:  newCircuitBreaker(5, 30*time.Second),
+		sleep:    sleepContext,
+	}
+}
+
+func (c *ResilientClient) VerifyRequest(ctx context.Context, r *model.HTTPRequest) error {
+	return c.next.VerifyRequest(ctx, r)
+}
+
+func (c *ResilientClient) ParseEvent(ctx context.Context, body []byte) (model.Event, error) {
+	return c.next.ParseEvent(ctx, body)
+}
+
+func (c *ResilientClient) ParseInteraction(ctx context.Context, body []byte) (model.Event, error) {
+	return c.next.ParseInteraction(ctx, body)
+}
+
+func (c *ResilientClient) ParseCommand(ctx context.Context, body []byte) (model.Event, error) {
+	return c.next.ParseCommand(ctx, body)
+}
+
+func (c *ResilientClient) PostMessage(ctx context.Context, message *model.Message) (string, error) {
+	var timestamp string
+	err := c.call(ctx, "chat.postMessage", func() error {
+		var err error
+		timestamp, err = c.next.PostMessage(ctx, message)
+		return err
+	})
+	return timestamp, err
+}
+
+func (c *ResilientClient) ReplaceMessage(ctx context.Context, message *model.Message, timestamp, responseURL string) error {
+	return c.call(ctx, "chat.update", func() error {
+		return c.next.ReplaceMessage(ctx, message, timestamp, responseURL)
+	})
+}
+
+func (c *ResilientClient) GetThreadText(ctx context.Context, channelID, threadTS string) (string, error) {
+	var text string
+	err := c.call(ctx, "conversations.replies", func() error {
+		var err error
+		text, err = c.next.GetThreadText(ctx, channelID, threadTS)
+		return err
+	})
+	return text, err
+}
+
+func (c *ResilientClient) FilterOnlineMemberIDs(ctx context.Context, memberIDs []model.MemberID) ([]model.MemberID, error) {
+	var onlineMemberIDs []model.MemberID
+	err := c.call(ctx, "users.getPresence", func() error {
+		var err error
+		onlineMemberIDs, err = c.next.FilterOnlineMemberIDs(ctx, memberIDs)
+		return err
+	})
+	return onlineMemberIDs, err
+}
+
+// TestAuth bypasses the retries and the circuit breaker: the health check calls it periodically anyway,
+// and its failures must not stop the calls that post messages
+func (c *ResilientClient) TestAuth(ctx context.Context) error {
+	return c.next.TestAuth(ctx)
+}
+
+func (c *ResilientClient) GetUserLocale(ctx context.Context, memberID model.MemberID) (string, error) {
+	var locale string
+	err := c.call(ctx, "users.info", func() error {
+		var err error
+		locale, err = c.next.GetUserLocale(ctx, memberID)
+		return err
+	})
+	return locale, err
+}
+
+func (c *ResilientClient) GetWorkspaceURL(ctx context.Context) (string, error) {
+	var workspaceURL string
+	err := c.call(ctx, "auth.test", func() error {
+		var err error
+		workspaceURL, err = c.next.GetWorkspaceURL(ctx)
+		return err
+	})
+	return workspaceURL, err
+}
+
+// call invokes fn, retrying transient failures according to the method's policy until ctx is done
+func (c *ResilientClient) call(ctx context.Context, method string, fn func() error) error {
+	if !c.breaker.allow() {
+		slog.WarnContext(ctx, "skipping slack api call while circuit breaker is open", "method", method)
+		return ErrCircuitOpen
+	}
+	policy := c.policies[method]
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	var err error
+	var attempt int
+	for attempt = 1; ; attempt++ {
+		err = fn()
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			// The caller gave up; this says nothing about Slack's health
+			c.breaker.abandon()
+			return err
+		}
+		if err == nil || !isRetryable(err) {
+			// Slack answered, so the circuit stays closed even for a non-retryable error
+			c.breaker.success()
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			break
+		}
+		if policy.NotIdempotent && mayHaveBeenHandled(err) {
+			// Slack may have carried the call out, and doing so again could post a message twice
+			break
+		}
+		delay := retryDelay(err, policy, attempt)
+		slog.WarnContext(
+			ctx,
+			"retrying slack api call",
+			"method", method,
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+		if err := c.sleep(ctx, delay); err != nil {
+			// The caller gave up; this says nothing about Slack's health
+			c.breaker.abandon()
+			return err
+		}
+	}
+	c.breaker.failure()
+	slog.ErrorContext(ctx, "slack api call failed after retries", "method", method, "attempts", attempt, "error", err)
+	return err
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt.
+// Slack's Retry-After is honored as is; other failures use exponential backoff with jitter.
+func retryDelay(err error, policy RetryPolicy, attempt int) time.Duration {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		return rateLimited.RetryAfter
+	}
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	// Equal jitter: wait between half and all of the computed delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// mayHaveBeenHandled reports whether Slack may have carried out a call that failed with err.
+// Slack answered any error but a network one, and only a failure to connect is known to have left it untouched.
+func mayHaveBeenHandled(err error) bool {
+	var netErr net.Error
+	if !errors.As(err, &netErr) {
+		return false
+	}
+	var opErr *net.OpError
+	return !errors.As(err, &opErr) || opErr.Op != "dial"
+}
+
+// isRetryable reports whether err is a transient failure worth retrying
+func isRetryable(err error) bool {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return true
+	}
+	var statusCode slack.StatusCodeError
+	if errors.As(err, &statusCode) {
+		return statusCode.Code >= 500
+	}
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return retryableSlackErrors[slackErr.Err]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return retryableSlackErrors[err.Error()]
+}
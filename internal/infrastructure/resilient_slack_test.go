@@ -0,0 +1,309 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/slack-go/slack"
+)
+
+// fakeSlack is a local stand-in for the Slack Web API answering each call with the next of its handlers,
+// and with the last one once they run out
+type fakeSlack struct {
+	server   *httptest.Server
+	calls    atomic.Int32
+	handlers []http.HandlerFunc
+}
+
+func newFakeSlack(t *testing.T, handlers ...http.HandlerFunc) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{handlers: handlers}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(f.calls.Add(1))
+		f.handlers[min(call, len(f.handlers))-1](w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns a Slack client calling the fake, giving up on calls after timeout if it is not zero
+func (f *fakeSlack) client(timeout time.Duration) *Client {
+	return &Client{
+		api: slack.New(
+			"xoxb-test",
+			slack.OptionAPIURL(f.server.URL+"/"),
+			slack.OptionHTTPClient(&http.Client{Timeout: timeout}),
+		),
+	}
+}
+
+func slackOK(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func slackStatus(code int, header map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		for name, value := range header {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(code)
+	}
+}
+
+// sleepRecorder stands in for sleeping between attempts, remembering the delays
+type sleepRecorder struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (s *sleepRecorder) sleep(ctx context.Context, d time.Duration) error {
+	s.mu.Lock()
+	s.delays = append(s.delays, d)
+	s.mu.Unlock()
+	return ctx.Err()
+}
+
+func newTestResilientClient(next *Client, breaker *circuitBreaker) (*ResilientClient, *sleepRecorder) {
+	recorder := &sleepRecorder{}
+	return &ResilientClient{
+		next:     next,
+		policies: retryPolicies,
+		breaker:  breaker,
+		sleep:    recorder.sleep,
+	}, recorder
+}
+
+const threadReplies = `{"ok": true, "messages": [{"text": "please review"}], "has_more": false}`
+
+func TestResilientClientHonorsRetryAfter(t *testing.T) {
+	slackAPI := newFakeSlack(t,
+		slackStatus(http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}),
+		slackOK(threadReplies),
+	)
+	client, recorder := newTestResilientClient(slackAPI.client(0), newCircuitBreaker(5, time.Minute))
+
+	text, err := client.GetThreadText(context.Background(), "C1", "1.0")
+	if err != nil || text != "please review" {
+		t.Fatalf("GetThreadText() = %q, %v", text, err)
+	}
+	if got := slackAPI.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+	if len(recorder.delays) != 1 || recorder.delays[0] != 7*time.Second {
+		t.Errorf("delays = %v, want the 7s of Retry-After", recorder.delays)
+	}
+}
+
+func TestResilientClientBacksOffExponentiallyOnServerErrors(t *testing.T) {
+	slackAPI := newFakeSlack(t, slackStatus(http.StatusInternalServerError, nil))
+	breaker := newCircuitBreaker(5, time.Minute)
+	client, recorder := newTestResilientClient(slackAPI.client(0), breaker)
+
+	var statusCode slack.StatusCodeError
+	if _, err := client.GetThreadText(context.Background(), "C1", "1.0"); !errors.As(err, &statusCode) {
+		t.Fatalf("GetThreadText() error = %v, want the status code error", err)
+	}
+	policy := retryPolicies["conversations.replies"]
+	if got := slackAPI.calls.Load(); got != int32(policy.MaxAttempts) {
+		t.Errorf("calls = %d, want %d", got, policy.MaxAttempts)
+	}
+	if len(recorder.delays) != policy.MaxAttempts-1 {
+		t.Fatalf("delays = %v, want %d", recorder.delays, policy.MaxAttempts-1)
+	}
+	for i, delay := range recorder.delays {
+		// Equal jitter waits between half and all of the doubled delay
+		full := policy.BaseDelay << i
+		if delay < full/2 || delay > full {
+			t.Errorf("delay %d = %v, want between %v and %v", i+1, delay, full/2, full)
+		}
+	}
+	if breaker.failures != 1 {
+		t.Errorf("breaker failures = %d, want 1 for the call as a whole", breaker.failures)
+	}
+}
+
+func TestResilientClientDoesNotRepeatPostedMessagesAfterTimeouts(t *testing.T) {
+	slackAPI := newFakeSlack(t, func(w http.ResponseWriter, r *http.Request) {
+		// Slack accepts the message but answers too late
+		time.Sleep(200 * time.Millisecond)
+		slackOK(`{"ok": true, "channel": "C1", "ts": "2.0"}`)(w, r)
+	})
+	client, recorder := newTestResilientClient(slackAPI.client(50*time.Millisecond), newCircuitBreaker(5, time.Minute))
+
+	if _, err := client.PostMessage(context.Background(), &model.Message{ChannelID: "C1", Text: "hello"}); err == nil {
+		t.Fatal("PostMessage() succeeded despite the timeout")
+	}
+	if got := slackAPI.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 so that the message is not posted twice", got)
+	}
+	if len(recorder.delays) != 0 {
+		t.Errorf("delays = %v, want none", recorder.delays)
+	}
+}
+
+func TestResilientClientRetriesPostedMessagesWhenSlackCannotBeReached(t *testing.T) {
+	slackAPI := newFakeSlack(t, slackOK(`{"ok": true}`))
+	next := slackAPI.client(0)
+	// Nothing listens on the address anymore, so the calls never reach Slack
+	slackAPI.server.Close()
+	client, recorder := newTestResilientClient(next, newCircuitBreaker(5, time.Minute))
+
+	if _, err := client.PostMessage(context.Background(), &model.Message{ChannelID: "C1", Text: "hello"}); err == nil {
+		t.Fatal("PostMessage() succeeded without a server")
+	}
+	if want := retryPolicies["chat.postMessage"].MaxAttempts - 1; len(recorder.delays) != want {
+		t.Errorf("delays = %v, want %d retries", recorder.delays, want)
+	}
+}
+
+func TestResilientClientCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	slackAPI := newFakeSlack(t, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			slackStatus(http.StatusServiceUnavailable, nil)(w, r)
+			return
+		}
+		slackOK(threadReplies)(w, r)
+	})
+	now := time.Unix(0, 0)
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	client, _ := newTestResilientClient(slackAPI.client(0), breaker)
+	ctx := context.Background()
+	callsPerFailure := int32(retryPolicies["conversations.replies"].MaxAttempts)
+
+	// closed -> open after as many failed calls as the threshold
+	for range 2 {
+		if _, err := client.GetThreadText(ctx, "C1", "1.0"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("GetThreadText() error = %v, want Slack's error", err)
+		}
+	}
+	if breaker.state != circuitOpen {
+		t.Fatalf("state = %v, want open", breaker.state)
+	}
+	// open: calls fail without reaching Slack until the cooldown has passed
+	if _, err := client.GetThreadText(ctx, "C1", "1.0"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GetThreadText() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := slackAPI.calls.Load(); got != 2*callsPerFailure {
+		t.Fatalf("calls = %d, want %d", got, 2*callsPerFailure)
+	}
+	// open -> half-open -> open when the trial call fails
+	now = now.Add(time.Minute)
+	if _, err := client.GetThreadText(ctx, "C1", "1.0"); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("trial GetThreadText() error = %v, want Slack's error", err)
+	}
+	if breaker.state != circuitOpen {
+		t.Fatalf("state = %v after a failed trial, want open", breaker.state)
+	}
+	// open -> half-open -> closed when the trial call succeeds
+	now = now.Add(time.Minute)
+	failing.Store(false)
+	if text, err := client.GetThreadText(ctx, "C1", "1.0"); err != nil || text != "please review" {
+		t.Fatalf("trial GetThreadText() = %q, %v", text, err)
+	}
+	if breaker.state != circuitClosed || breaker.failures != 0 {
+		t.Fatalf("state = %v with %d failures after a successful trial, want closed", breaker.state, breaker.failures)
+	}
+}
+
+func TestResilientClientReopensCircuitWhenTrialCallIsCancelled(t *testing.T) {
+	slackAPI := newFakeSlack(t, slackStatus(http.StatusServiceUnavailable, nil))
+	now := time.Unix(0, 0)
+	breaker := newCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+	breaker.failure()
+	client, _ := newTestResilientClient(slackAPI.client(0), breaker)
+	ctx, cancel := context.WithCancel(context.Background())
+	// The caller gives up while the trial call waits to be retried
+	client.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := client.GetThreadText(ctx, "C1", "1.0"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("trial GetThreadText() error = %v, want context.Canceled", err)
+	}
+	if breaker.state != circuitOpen {
+		t.Fatalf("state = %v after a cancelled trial, want open", breaker.state)
+	}
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("allow() = false after the cooldown following a cancelled trial")
+	}
+}
+
+func TestResilientClientDoesNotCountCancelledCalls(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	released := make(chan struct{})
+	slackAPI := newFakeSlack(t, func(w http.ResponseWriter, r *http.Request) {
+		// Slack is slow and the caller gives up waiting
+		cancel()
+		<-released
+	})
+	// Cleanups run last to first, so the handler returns before the server closes
+	t.Cleanup(func() { close(released) })
+	breaker := newCircuitBreaker(1, time.Minute)
+	client, recorder := newTestResilientClient(slackAPI.client(0), breaker)
+
+	if _, err := client.GetThreadText(ctx, "C1", "1.0"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetThreadText() error = %v, want context.Canceled", err)
+	}
+	if got := slackAPI.calls.Load(); got != 1 || len(recorder.delays) != 0 {
+		t.Errorf("calls = %d with delays %v, want a single call", got, recorder.delays)
+	}
+	if breaker.state != circuitClosed || breaker.failures != 0 {
+		t.Errorf("state = %v with %d failures, want closed without failures", breaker.state, breaker.failures)
+	}
+}
+
+func TestResilientClientTestAuthBypassesRetriesAndBreaker(t *testing.T) {
+	slackAPI := newFakeSlack(t, slackStatus(http.StatusServiceUnavailable, nil))
+	breaker := newCircuitBreaker(1, time.Minute)
+	client, recorder := newTestResilientClient(slackAPI.client(0), breaker)
+
+	for range 3 {
+		if err := client.TestAuth(context.Background()); err == nil {
+			t.Fatal("TestAuth() succeeded while Slack failed")
+		}
+	}
+	if got := slackAPI.calls.Load(); got != 3 || len(recorder.delays) != 0 {
+		t.Errorf("calls = %d with delays %v, want one call per check", got, recorder.delays)
+	}
+	if breaker.state != circuitClosed || breaker.failures != 0 {
+		t.Errorf("state = %v with %d failures, want failed health checks to leave the circuit closed", breaker.state, breaker.failures)
+	}
+}
+
+func TestCircuitBreakerLetsOneTrialCallThrough(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := newCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.failure()
+	if breaker.allow() {
+		t.Fatal("allow() = true right after opening")
+	}
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("allow() = false after the cooldown")
+	}
+	if breaker.state != circuitHalfOpen {
+		t.Fatalf("state = %v, want half-open", breaker.state)
+	}
+	if breaker.allow() {
+		t.Fatal("allow() = true while the trial call is in flight")
+	}
+}
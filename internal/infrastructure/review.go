@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+const reviewBucket = "reviews"
+
+// reviewBatchSize is how many reviews Each reads at once.
+// fn runs between the reads so that a slow caller, e.g. an export to a slow client, does not keep a read open.
+const reviewBatchSize = 100
+
+// ReviewStore is a ReviewRepository backed by a KVStore
+type ReviewStore struct {
+	kv KVStore
+}
+
+var _ repository.ReviewRepository = (*ReviewStore)(nil)
+
+func NewReviewStore(kv KVStore) *ReviewStore {
+	return &ReviewStore{
+		kv: kv,
+	}
+}
+
+func (s *ReviewStore) Get(id string) (*model.Review, error) {
+	b, err := s.kv.Get(reviewBucket, id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, repository.ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var review model.Review
+	if err := json.Unmarshal(b, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (s *ReviewStore) Save(review *model.Review) error {
+	next := *review
+	next.Version++
+	err := s.kv.Update(reviewBucket, review.ID, func(current []byte) ([]byte, error) {
+		storedVersion := 0
+		if current != nil {
+			var stored model.Review
+			if err := json.Unmarshal(current, &stored); err != nil {
+				return nil, err
+			}
+			storedVersion = stored.Version
+		}
+		if storedVersion != review.Version {
+			return nil, repository.ErrReviewConflict
+		}
+		return json.Marshal(&next)
+	})
+	if err != nil {
+		return err
+	}
+	review.Version = next.Version
+	return nil
+}
+
+func (s *ReviewStore) Ping() error {
+	return s.kv.Ping()
+}
+
+func (s *ReviewStore) List() ([]*model.Review, error) {
+	var reviews []*model.Review
+	err := s.Each(func(review *model.Review) error {
+		reviews = append(reviews, review)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (s *ReviewStore) Each(fn func(review *model.Review) error) error {
+	after := ""
+	for {
+		batch := make([]*model.Review, 0, reviewBatchSize)
+		err := s.kv.Scan(reviewBucket, after, reviewBatchSize, func(key string, value []byte) error {
+			var review model.Review
+			if err := json.Unmarshal(value, &review); err != nil {
+				return err
+			}
+			batch = append(batch, &review)
+			after = key
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, review := range batch {
+			if err := fn(review); err != nil {
+				return err
+			}
+		}
+		if len(batch) < reviewBatchSize {
+			return nil
+		}
+	}
+}
+
+// InstrumentedReviewStore decorates a ReviewStore with metrics on the assignments it records
+type InstrumentedReviewStore struct {
+	*ReviewStore
+	metrics *Metrics
+}
+
+var _ repository.ReviewRepository = (*InstrumentedReviewStore)(nil)
+
+func NewInstrumentedReviewStore(store *ReviewStore, metrics *Metrics) *InstrumentedReviewStore {
+	return &InstrumentedReviewStore{
+		ReviewStore: store,
+		metrics:     metrics,
+	}
+}
+
+func (s *InstrumentedReviewStore) Save(review *model.Review) error {
+	if err := s.ReviewStore.Save(review); err != nil {
+		return err
+	}
+	// Reviews are only saved as assigned when a reviewer has just been assigned
+	if review.Status == model.ReviewStatusAssigned {
+		s.metrics.assignments.WithLabelValues(string(review.Mode)).Inc()
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+const (
+	reviewerBucket = "reviewers"
+	rosterKey      = "roster"
+)
+
+// ReviewerStore is a ReviewerRepository backed by a KVStore.
+// The roster is stored as a single value so that it can be validated as a whole on every change.
+// Until it is first changed, the roster is the one in the reviewer configuration files.
+type ReviewerStore struct {
+	kv      KVStore
+	initial model.ReviewerMap
+	logins  model.CodeHostLogins
+}
+
+var _ repository.ReviewerRepository = (*ReviewerStore)(nil)
+
+func NewReviewerStore(kv KVStore, initial model.ReviewerMap, logins model.CodeHostLogins) *ReviewerStore {
+	return &ReviewerStore{
+		kv:      kv,
+		initial: initial,
+		logins:  logins,
+	}
+}
+
+func (s *ReviewerStore) GetRoster() (*model.Roster, error) {
+	b, err := s.kv.Get(reviewerBucket, rosterKey)
+	if errors.Is(err, ErrKeyNotFound) {
+		return model.NewRoster(s.initial, s.logins), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(b)
+}
+
+func (s *ReviewerStore) UpdateRoster(fn func(roster *model.Roster) error) error {
+	return s.kv.Update(reviewerBucket, rosterKey, func(current []byte) ([]byte, error) {
+		roster := model.NewRoster(s.initial, s.logins)
+		if current != nil {
+			var err error
+			if roster, err = s.decode(current); err != nil {
+				return nil, err
+			}
+		}
+		if err := fn(roster); err != nil {
+			return nil, err
+		}
+		return json.Marshal(roster)
+	})
+}
+
+func (s *ReviewerStore) decode(b []byte) (*model.Roster, error) {
+	var roster model.Roster
+	if err := json.Unmarshal(b, &roster); err != nil {
+		return nil, err
+	}
+	return &roster, nil
+}
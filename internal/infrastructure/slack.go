@@ -1,57 +1,103 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
 	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type Client struct {
-	api           *slack.Client
-	signingSecret model.SigningSecret
+	api            *slack.Client
+	signingSecrets repository.SigningSecretRepository
+	metrics        *Metrics
+
+	mu sync.Mutex
+	// workspaceURL is remembered from the last successful auth.test
+	workspaceURL string
 }
 
 var _ repository.SlackRepository = (*Client)(nil)
 
-func NewClient(oauthToken model.OAuthToken, signingSecret model.SigningSecret) *Client {
+func NewClient(oauthToken model.OAuthToken, signingSecrets repository.SigningSecretRepository, metrics *Metrics) *Client {
 	return &Client{
-		api:           slack.New(string(oauthToken)),
-		signingSecret: signingSecret,
+		api: slack.New(
+			string(oauthToken),
+			// Trace the HTTP requests made to the Web API
+			slack.OptionHTTPClient(&http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}),
+		),
+		signingSecrets: signingSecrets,
+		metrics:        metrics,
 	}
 }
 
-func (c *Client) VerifyRequest(r *model.HTTPRequest) error {
-	sv, err := slack.NewSecretsVerifier(r.Headers, string(c.signingSecret))
-	if err != nil {
-		slog.Error("failed to create secrets verifier", "error", err)
+// VerifyRequest accepts requests signed with the primary signing secret or any unexpired previous one
+func (c *Client) VerifyRequest(ctx context.Context, r *model.HTTPRequest) error {
+	secrets := c.signingSecrets.GetSigningSecrets()
+	err := verifySignature(r, secrets.Primary)
+	if err == nil {
+		slog.InfoContext(ctx, "request verified successfully", "signing_secret", "primary")
+		c.metrics.signingSecrets.WithLabelValues("primary").Inc()
+		return nil
+	}
+	// Missing headers or a stale timestamp fail the same way for every secret
+	if errors.Is(err, slack.ErrMissingHeaders) || errors.Is(err, slack.ErrExpiredTimestamp) {
+		slog.ErrorContext(ctx, "failed to verify request", "error", err)
 		return err
 	}
-	if _, err = sv.Write(r.Body); err != nil {
-		slog.Error("failed to write body to verifier", "error", err)
+	now := time.Now()
+	for i, previous := range secrets.Previous {
+		// The index is that of SLACK_PREVIOUS_SIGNING_SECRETS, expired entries included
+		if previous.Active(now) && verifySignature(r, previous.Secret) == nil {
+			slog.WarnContext(
+				ctx,
+				"request verified with previous signing secret",
+				"signing_secret", "previous["+strconv.Itoa(i)+"]",
+				"expires_at", previous.ExpiresAt,
+			)
+			c.metrics.signingSecrets.WithLabelValues("previous").Inc()
+			return nil
+		}
+	}
+	slog.ErrorContext(ctx, "failed to verify request", "error", err)
+	return err
+}
+
+func verifySignature(r *model.HTTPRequest, secret model.SigningSecret) error {
+	sv, err := slack.NewSecretsVerifier(http.Header(r.Headers), string(secret))
+	if err != nil {
 		return err
 	}
-	if err := sv.Ensure(); err != nil {
-		slog.Error("failed to verify request", "error", err)
+	if _, err = sv.Write(r.Body); err != nil {
 		return err
 	}
-	slog.Info("request verified successfully")
-	return nil
+	return sv.Ensure()
 }
 
-func (c *Client) ParseEvent(body []byte) (model.Event, error) {
+func (c *Client) ParseEvent(ctx context.Context, body []byte) (model.Event, error) {
 	// Parse regular Slack events
 	eventsAPIEvent, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
 	if err != nil {
-		slog.Error("failed to parse event", "error", err)
+		slog.ErrorContext(ctx, "failed to parse event", "error", err)
 		return nil, err
 	}
 	switch eventsAPIEvent.Type {
 	case slackevents.CallbackEvent:
+		var eventID string
+		if cb, ok := eventsAPIEvent.Data.(*slackevents.EventsAPICallbackEvent); ok {
+			eventID = cb.EventID
+		}
 		innerEvent := eventsAPIEvent.InnerEvent
 		switch ev := innerEvent.Data.(type) {
 		case *slackevents.AppMentionEvent:
@@ -60,25 +106,37 @@ func (c *Client) ParseEvent(body []byte) (model.Event, error) {
 			if threadTS == "" {
 				threadTS = ev.TimeStamp
 			}
-			return model.NewAppMentionEvent(ev.Channel, threadTS), nil
+			return model.NewAppMentionEvent(eventID, eventsAPIEvent.TeamID, ev.Channel, threadTS, model.MemberID(ev.User), ev.Text), nil
+		case *slackevents.ReactionAddedEvent:
+			if ev.Item.Type != "message" {
+				return nil, nil
+			}
+			return model.NewReactionAddedEvent(
+				eventID,
+				eventsAPIEvent.TeamID,
+				ev.Item.Channel,
+				ev.Item.Timestamp,
+				model.MemberID(ev.User),
+				ev.Reaction,
+			), nil
 		default:
-			slog.Info("unsupported inner event type", "type", ev)
+			slog.InfoContext(ctx, "unsupported inner event type", "type", ev)
 			return nil, nil
 		}
 	case slackevents.URLVerification:
 		var r *slackevents.ChallengeResponse
 		if err := json.Unmarshal(body, &r); err != nil {
-			slog.Error("failed to parse challenge", "error", err)
+			slog.ErrorContext(ctx, "failed to parse challenge", "error", err)
 			return nil, err
 		}
 		return model.NewURLVerificationEvent(r.Challenge), nil
 	default:
-		slog.Info("unsupported event type", "type", eventsAPIEvent.Type)
+		slog.InfoContext(ctx, "unsupported event type", "type", eventsAPIEvent.Type)
 		return nil, nil
 	}
 }
 
-func (c *Client) ParseInteraction(body []byte) (model.Event, error) {
+func (c *Client) ParseInteraction(ctx context.Context, body []byte) (model.Event, error) {
 	payloadStr := string(body)
 	if len(payloadStr) <= 8 || payloadStr[:8] != "payload=" {
 		return nil, nil
@@ -86,12 +144,12 @@ func (c *Client) ParseInteraction(body []byte) (model.Event, error) {
 	// URL decode and remove "payload=" prefix
 	decoded, err := url.QueryUnescape(payloadStr[8:])
 	if err != nil {
-		slog.Error("failed to unescape payload", "error", err)
+		slog.ErrorContext(ctx, "failed to unescape payload", "error", err)
 		return nil, err
 	}
 	var interaction slack.InteractionCallback
 	if err := json.Unmarshal([]byte(decoded), &interaction); err != nil {
-		slog.Error("failed to parse interaction", "error", err)
+		slog.ErrorContext(ctx, "failed to parse interaction", "error", err)
 		return nil, err
 	}
 	if len(interaction.ActionCallback.AttachmentActions) == 0 {
@@ -99,7 +157,7 @@ func (c *Client) ParseInteraction(body []byte) (model.Event, error) {
 	}
 	action := interaction.ActionCallback.AttachmentActions[0]
 	var value string
-	if action.Name == "random_reviewer" || action.Name == "urgent_reviewer" {
+	if action.Name == "random_reviewer" || action.Name == "urgent_reviewer" || action.Name == "suggested_reviewer" {
 		value = "" // Empty value indicates random selection
 	} else if action.Name == "select_reviewer" && len(action.SelectedOptions) > 0 {
 		value = action.SelectedOptions[0].Value
@@ -110,18 +168,39 @@ func (c *Client) ParseInteraction(body []byte) (model.Event, error) {
 		threadTS = interaction.OriginalMessage.Timestamp
 	}
 	return model.NewInteractiveMessageEvent(
+		interaction.Team.ID,
 		interaction.Channel.ID,
 		action.Name,
+		interaction.ActionTs,
 		value,
 		interaction.MessageTs,
 		threadTS,
 		model.MemberID(interaction.User.ID),
+		interaction.ResponseURL,
 	), nil
 }
 
-func (c *Client) PostMessage(message *model.Message) error {
-	var options []slack.MsgOption
-	options = append(options, slack.MsgOptionText(message.Text, false))
+func (c *Client) ParseCommand(ctx context.Context, body []byte) (model.Event, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to parse slash command", "error", err)
+		return nil, err
+	}
+	if values.Get("command") == "" {
+		return nil, nil
+	}
+	return model.NewSlashCommandEvent(
+		values.Get("team_id"),
+		values.Get("channel_id"),
+		model.MemberID(values.Get("user_id")),
+		values.Get("command"),
+		values.Get("text"),
+		values.Get("response_url"),
+	), nil
+}
+
+func (c *Client) PostMessage(ctx context.Context, message *model.Message) (string, error) {
+	options := messageOptions(message)
 	// When ThreadTS is set, ensure the message is posted in that thread
 	if message.ThreadTS != "" {
 		options = append(options, slack.MsgOptionPostMessageParameters(slack.PostMessageParameters{
@@ -129,78 +208,170 @@ func (c *Client) PostMessage(message *model.Message) error {
 		}))
 	}
 
-	if len(message.Attachments) > 0 {
-		var attachments []slack.Attachment
-		for _, a := range message.Attachments {
-			var actions []slack.AttachmentAction
-			for _, act := range a.Actions {
-				action := slack.AttachmentAction{
-					Name:  act.Name,
-					Text:  act.Text,
-					Type:  slack.ActionType(act.Type),
-					Value: act.Value,
-				}
-				if len(act.Options) > 0 {
-					actionOptions := make([]slack.AttachmentActionOption, len(act.Options))
-					for i, opt := range act.Options {
-						actionOptions[i] = slack.AttachmentActionOption{
-							Text:  opt.Text,
-							Value: opt.Value,
-						}
-					}
-					action.Options = actionOptions
-				}
-				actions = append(actions, action)
-			}
-			attachment := slack.Attachment{
-				Text:       a.Text,
-				CallbackID: a.CallbackID,
-				Actions:    actions,
-				Color:      a.Color,
-				Fields:     make([]slack.AttachmentField, len(a.Fields)),
-			}
-			// Convert Fields
-			for i, f := range a.Fields {
-				attachment.Fields[i] = slack.AttachmentField{
-					Title: f.Title,
-					Value: f.Value,
-					Short: f.Short,
-				}
-			}
-			attachments = append(attachments, attachment)
-		}
-		options = append(options, slack.MsgOptionAttachments(attachments...))
-	}
-
-	_, _, err := c.api.PostMessage(
+	_, timestamp, err := c.api.PostMessageContext(
+		ctx,
 		message.ChannelID,
 		options...,
 	)
 	if err != nil {
-		slog.Error("failed to post message", "error", err)
+		slog.ErrorContext(ctx, "failed to post message", "error", err)
+		return "", err
+	}
+	slog.InfoContext(ctx, "message posted successfully", "channel", message.ChannelID)
+	return timestamp, nil
+}
+
+func (c *Client) ReplaceMessage(ctx context.Context, message *model.Message, timestamp, responseURL string) error {
+	options := messageOptions(message)
+	if responseURL != "" {
+		_, _, err := c.api.PostMessageContext(
+			ctx,
+			message.ChannelID,
+			append(options, slack.MsgOptionReplaceOriginal(responseURL))...,
+		)
+		if err == nil {
+			slog.InfoContext(ctx, "message replaced successfully", "channel", message.ChannelID)
+			return nil
+		}
+		if isRetryable(err) {
+			slog.ErrorContext(ctx, "failed to replace message", "error", err)
+			return err
+		}
+		// Response URLs expire after 30 minutes and five uses
+		slog.WarnContext(ctx, "failed to replace message through response URL, falling back to chat.update", "error", err)
+	}
+	// Pass the attachments even when empty so that chat.update removes the old ones
+	options = append(options, slack.MsgOptionAttachments(toSlackAttachments(message.Attachments)...))
+	_, _, _, err := c.api.UpdateMessageContext(ctx, message.ChannelID, timestamp, options...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update message", "error", err)
 		return err
 	}
-	slog.Info("message posted successfully", "channel", message.ChannelID)
+	slog.InfoContext(ctx, "message updated successfully", "channel", message.ChannelID)
 	return nil
 }
 
-func (c *Client) DeleteMessage(channelID, timestamp string) error {
-	_, _, err := c.api.DeleteMessage(channelID, timestamp)
+func (c *Client) GetThreadText(ctx context.Context, channelID, threadTS string) (string, error) {
+	messages, _, _, err := c.api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Limit:     1,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get thread", "error", err)
+		return "", err
+	}
+	// The message starting the thread comes first
+	if len(messages) == 0 {
+		return "", nil
+	}
+	return messages[0].Text, nil
+}
+
+func (c *Client) GetUserLocale(ctx context.Context, memberID model.MemberID) (string, error) {
+	// users.info includes the locale on request, which the library always makes
+	user, err := c.api.GetUserInfoContext(ctx, string(memberID))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get user info", "member_id", memberID, "error", err)
+		return "", err
+	}
+	return user.Locale, nil
+}
+
+func (c *Client) TestAuth(ctx context.Context) error {
+	response, err := c.api.AuthTestContext(ctx)
 	if err != nil {
-		slog.Error("failed to delete message", "error", err)
+		slog.ErrorContext(ctx, "failed to test auth", "error", err)
 		return err
 	}
-	slog.Info("message deleted successfully", "channel", channelID)
+	slog.DebugContext(ctx, "auth tested successfully", "team_id", response.TeamID, "user_id", response.UserID)
+	c.mu.Lock()
+	c.workspaceURL = response.URL
+	c.mu.Unlock()
 	return nil
 }
 
-func (c *Client) FilterOnlineMemberIDs(memberIDs []model.MemberID) ([]model.MemberID, error) {
+// GetWorkspaceURL returns the workspace URL reported by the last auth.test, calling it if there was none
+func (c *Client) GetWorkspaceURL(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	workspaceURL := c.workspaceURL
+	c.mu.Unlock()
+	if workspaceURL != "" {
+		return workspaceURL, nil
+	}
+	if err := c.TestAuth(ctx); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.workspaceURL, nil
+}
+
+// messageOptions converts the text and attachments of a message into Slack message options
+func messageOptions(message *model.Message) []slack.MsgOption {
+	var options []slack.MsgOption
+	options = append(options, slack.MsgOptionText(message.Text, false))
+	if len(message.Attachments) > 0 {
+		options = append(options, slack.MsgOptionAttachments(toSlackAttachments(message.Attachments)...))
+	}
+	return options
+}
+
+func toSlackAttachments(modelAttachments []model.Attachment) []slack.Attachment {
+	attachments := []slack.Attachment{}
+	for _, a := range modelAttachments {
+		var actions []slack.AttachmentAction
+		for _, act := range a.Actions {
+			action := slack.AttachmentAction{
+				Name:  act.Name,
+				Text:  act.Text,
+				Type:  slack.ActionType(act.Type),
+				Value: act.Value,
+			}
+			if len(act.Options) > 0 {
+				actionOptions := make([]slack.AttachmentActionOption, len(act.Options))
+				for i, opt := range act.Options {
+					actionOptions[i] = slack.AttachmentActionOption{
+						Text:  opt.Text,
+						Value: opt.Value,
+					}
+				}
+				action.Options = actionOptions
+			}
+			actions = append(actions, action)
+		}
+		attachment := slack.Attachment{
+			Text:       a.Text,
+			CallbackID: a.CallbackID,
+			Actions:    actions,
+			Color:      a.Color,
+			Fields:     make([]slack.AttachmentField, len(a.Fields)),
+		}
+		// Convert Fields
+		for i, f := range a.Fields {
+			attachment.Fields[i] = slack.AttachmentField{
+				Title: f.Title,
+				Value: f.Value,
+				Short: f.Short,
+			}
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+func (c *Client) FilterOnlineMemberIDs(ctx context.Context, memberIDs []model.MemberID) ([]model.MemberID, error) {
 	var onlineMemberIDs []model.MemberID
 	for _, memberID := range memberIDs {
 		// Get user presence
-		presence, err := c.api.GetUserPresence(string(memberID))
+		presence, err := c.api.GetUserPresenceContext(ctx, string(memberID))
 		if err != nil {
-			slog.Warn("failed to get user presence", "user_id", memberID, "error", err)
+			// Transient failures abort the lookup so that it can be retried as a whole
+			if isRetryable(err) {
+				slog.ErrorContext(ctx, "failed to get user presence", "user_id", memberID, "error", err)
+				return nil, err
+			}
+			slog.WarnContext(ctx, "failed to get user presence", "user_id", memberID, "error", err)
 			continue
 		}
 		// Check if user is active/online
@@ -208,6 +379,6 @@ func (c *Client) FilterOnlineMemberIDs(memberIDs []model.MemberID) ([]model.Memb
 			onlineMemberIDs = append(onlineMemberIDs, memberID)
 		}
 	}
-	slog.Info("found online members", "input_count", len(memberIDs), "online_count", len(onlineMemberIDs))
+	slog.InfoContext(ctx, "found online members", "input_count", len(memberIDs), "online_count", len(onlineMemberIDs))
 	return onlineMemberIDs, nil
 }
@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	slackAuthorizeURL = "https://slack.com/openid/connect/authorize"
+	slackIssuer       = "https://slack.com"
+)
+
+// SlackSignInOptions configures Sign in with Slack
+type SlackSignInOptions struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where Slack sends the browser back to with the authorization code
+	RedirectURL string
+	// TeamID preselects the workspace on Slack's sign-in page
+	TeamID string
+}
+
+// SlackSignInClient implements Sign in with Slack, Slack's OpenID Connect provider
+type SlackSignInClient struct {
+	httpClient *http.Client
+	options    SlackSignInOptions
+}
+
+var _ repository.SignInRepository = (*SlackSignInClient)(nil)
+
+func NewSlackSignInClient(options SlackSignInOptions) *SlackSignInClient {
+	return &SlackSignInClient{
+		httpClient: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		options:    options,
+	}
+}
+
+func (c *SlackSignInClient) AuthorizationURL(state, nonce string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"scope":         {"openid profile"},
+		"client_id":     {c.options.ClientID},
+		"redirect_uri":  {c.options.RedirectURL},
+		"state":         {state},
+		"nonce":         {nonce},
+		"team":          {c.options.TeamID},
+	}
+	return slackAuthorizeURL + "?" + query.Encode()
+}
+
+// idTokenClaims are the claims of Slack's ID tokens used for signing in
+type idTokenClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	Nonce     string   `json:"nonce"`
+	TeamID    string   `json:"https://slack.com/team_id"`
+	Name      string   `json:"name"`
+}
+
+// audience is the aud claim, which may be a single string or an array
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// Exchange redeems the code at openid.connect.token. The ID token comes straight from Slack over TLS,
+// so its signature need not be verified (OpenID Connect Core 3.1.3.7), but its claims are.
+func (c *SlackSignInClient) Exchange(ctx context.Context, code, nonce string) (*model.SlackIdentity, error) {
+	response, err := slack.GetOpenIDConnectTokenContext(ctx, c.httpClient, c.options.ClientID, c.options.ClientSecret, code, c.options.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	parts := strings.Split(response.IdToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token: %w", err)
+	}
+	var claims idTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token: %w", err)
+	}
+	switch {
+	case claims.Issuer != slackIssuer:
+		return nil, fmt.Errorf("ID token issued by %q", claims.Issuer)
+	case !claims.Audience.contains(c.options.ClientID):
+		return nil, errors.New("ID token issued for another client")
+	case time.Now().After(time.Unix(claims.ExpiresAt, 0)):
+		return nil, errors.New("ID token expired")
+	case claims.Nonce != nonce:
+		return nil, errors.New("ID token nonce does not match")
+	case claims.Subject == "":
+		return nil, errors.New("ID token has no subject")
+	}
+	return &model.SlackIdentity{
+		MemberID: model.MemberID(claims.Subject),
+		TeamID:   claims.TeamID,
+		Name:     claims.Name,
+	}, nil
+}
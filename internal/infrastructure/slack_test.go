@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type staticSigningSecrets model.SigningSecrets
+
+func (s staticSigningSecrets) GetSigningSecrets() model.SigningSecrets {
+	return model.SigningSecrets(s)
+}
+
+// signedRequest signs the body with the secret the way Slack does at the given time
+func signedRequest(secret string, at time.Time, body string) *model.HTTPRequest {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return model.NewHTTPRequest([]byte(body), header)
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestClientVerifyRequest(t *testing.T) {
+	now := time.Now()
+	secrets := staticSigningSecrets{
+		Primary: "primary",
+		Previous: []model.PreviousSigningSecret{
+			{Secret: "expired", ExpiresAt: now.Add(-time.Hour)},
+			{Secret: "previous", ExpiresAt: now.Add(time.Hour)},
+		},
+	}
+	tests := []struct {
+		name       string
+		request    *model.HTTPRequest
+		wantErr    bool
+		wantSecret string
+	}{
+		{name: "primary secret", request: signedRequest("primary", now, "payload"), wantSecret: "primary"},
+		{name: "previous secret", request: signedRequest("previous", now, "payload"), wantSecret: "previous"},
+		{name: "expired previous secret", request: signedRequest("expired", now, "payload"), wantErr: true},
+		{name: "unknown secret", request: signedRequest("unknown", now, "payload"), wantErr: true},
+		{name: "stale timestamp", request: signedRequest("primary", now.Add(-time.Hour), "payload"), wantErr: true},
+		{name: "missing headers", request: model.NewHTTPRequest([]byte("payload"), http.Header{}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewMetrics(NewReviewStore(NewMemoryKVStore()))
+			client := NewClient("xoxb-test", secrets, metrics)
+			err := client.VerifyRequest(context.Background(), tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyRequest() error = %v, want error %v", err, tt.wantErr)
+			}
+			for _, secret := range []string{"primary", "previous"} {
+				want := 0.0
+				if secret == tt.wantSecret {
+					want = 1
+				}
+				if got := counterValue(t, metrics.signingSecrets.WithLabelValues(secret)); got != want {
+					t.Errorf("verifications with the %s secret = %v, want %v", secret, got, want)
+				}
+			}
+		})
+	}
+}
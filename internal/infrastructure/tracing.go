@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var tracer = otel.Tracer("github.com/himura467/slack-review-request-bot/internal/infrastructure")
+
+// TracingOptions configures trace export
+type TracingOptions struct {
+	// Enabled exports spans over OTLP/HTTP to the endpoint configured by the standard OTEL_EXPORTER_OTLP_* variables
+	Enabled bool
+	// ServiceName is reported as service.name unless OTEL_SERVICE_NAME overrides it
+	ServiceName string
+}
+
+// Tracing owns the global tracer provider
+type Tracing struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewTracing installs the global trace context propagator and, when enabled, a tracer provider exporting over OTLP.
+// Without export, spans are not recorded but incoming trace context is still propagated.
+func NewTracing(options TracingOptions) (*Tracing, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !options.Enabled {
+		return &Tracing{}, nil
+	}
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.New(
+		context.Background(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", options.ServiceName)),
+		// Let OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	slog.Info("exporting traces over OTLP", "service_name", options.ServiceName)
+	return &Tracing{provider: provider}, nil
+}
+
+// Shutdown flushes the spans that have not been exported yet
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
@@ -10,5 +10,25 @@ import (
 
 var Set = wire.NewSet(
 	NewClient,
-	wire.Bind(new(repository.SlackRepository), new(*Client)),
+	NewResilientClient,
+	NewInstrumentedClient,
+	NewPresenceCacheClient,
+	NewLocaleCacheClient,
+	wire.Bind(new(repository.SlackRepository), new(*LocaleCacheClient)),
+	NewJobStore,
+	wire.Bind(new(repository.JobRepository), new(*JobStore)),
+	NewWorkerPool,
+	wire.Bind(new(repository.JobQueue), new(*WorkerPool)),
+	NewReviewStore,
+	NewInstrumentedReviewStore,
+	wire.Bind(new(repository.ReviewRepository), new(*InstrumentedReviewStore)),
+	NewReviewerStore,
+	wire.Bind(new(repository.ReviewerRepository), new(*ReviewerStore)),
+	NewGitHubClient,
+	NewGitLabClient,
+	NewCodeHosts,
+	NewSlackSignInClient,
+	wire.Bind(new(repository.SignInRepository), new(*SlackSignInClient)),
+	NewMetrics,
+	NewTracing,
 )
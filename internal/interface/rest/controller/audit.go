@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// HandleListAuditEvents lists the audit log, optionally of a single review request given by thread (a message link)
+// or by channel and thread_ts, and between from and to in RFC 3339, returning at most limit events
+func (c *Controller) HandleListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	var query model.AuditQuery
+	channelID, threadTS := params.Get("channel"), params.Get("thread_ts")
+	if link := params.Get("thread"); link != "" {
+		var ok bool
+		if channelID, threadTS, ok = model.ParseThreadLink(link); !ok {
+			http.Error(w, "thread must be a Slack message link", http.StatusBadRequest)
+			return
+		}
+	}
+	if (channelID == "") != (threadTS == "") {
+		http.Error(w, "channel and thread_ts must be given together", http.StatusBadRequest)
+		return
+	}
+	if channelID != "" {
+		query = model.ReviewAuditQuery(channelID, threadTS)
+	}
+	for name, dst := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, name+" must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		*dst = t
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+	writeResponse(w, r, c.audit.ListAuditEvents(r.Context(), query))
+}
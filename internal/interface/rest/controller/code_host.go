@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// HandleGitHubWebhook handles the pull_request and pull_request_review webhook deliveries of GitHub
+func (c *Controller) HandleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	c.handleWebhook(w, r, model.CodeHostGitHub)
+}
+
+// HandleGitLabWebhook handles the merge request events of GitLab
+func (c *Controller) HandleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	c.handleWebhook(w, r, model.CodeHostGitLab)
+}
+
+func (c *Controller) handleWebhook(w http.ResponseWriter, r *http.Request, host model.CodeHost) {
+	request, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+	writeResponse(w, r, c.codeHost.HandleWebhook(r.Context(), host, request))
+}
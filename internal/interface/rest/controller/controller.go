@@ -1,15 +1,87 @@
 package controller
 
 import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
 	"github.com/himura467/slack-review-request-bot/internal/usecase"
 )
 
 type Controller struct {
-	slack usecase.SlackUsecase
+	slack     usecase.SlackUsecase
+	health    usecase.HealthUsecase
+	audit     usecase.AuditUsecase
+	stats     usecase.StatsUsecase
+	export    usecase.ExportUsecase
+	dashboard usecase.DashboardUsecase
+	reviewer  usecase.ReviewerUsecase
+	// reviewRequest and codeHost are implemented by the same usecase as slack
+	reviewRequest usecase.ReviewRequestUsecase
+	codeHost      usecase.CodeHostUsecase
+
+	sessions       sessionCodec
+	sessionOptions SessionOptions
 }
 
-func NewController(slack usecase.SlackUsecase) *Controller {
+func NewController(
+	slack usecase.SlackUsecase,
+	health usecase.HealthUsecase,
+	audit usecase.AuditUsecase,
+	stats usecase.StatsUsecase,
+	export usecase.ExportUsecase,
+	dashboard usecase.DashboardUsecase,
+	reviewer usecase.ReviewerUsecase,
+	reviewRequest usecase.ReviewRequestUsecase,
+	codeHost usecase.CodeHostUsecase,
+	sessionOptions SessionOptions,
+) *Controller {
 	return &Controller{
-		slack: slack,
+		slack:          slack,
+		health:         health,
+		audit:          audit,
+		stats:          stats,
+		export:         export,
+		dashboard:      dashboard,
+		reviewer:       reviewer,
+		reviewRequest:  reviewRequest,
+		codeHost:       codeHost,
+		sessions:       newSessionCodec(sessionOptions.Secret),
+		sessionOptions: sessionOptions,
+	}
+}
+
+// readRequest reads the body of r into an HTTPRequest, answering the request itself when that fails
+func readRequest(w http.ResponseWriter, r *http.Request) (*model.HTTPRequest, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			slog.WarnContext(r.Context(), "request body too large", "limit", maxBytesErr.Limit)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		slog.ErrorContext(r.Context(), "failed to read request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	return model.NewHTTPRequest(body, r.Header), true
+}
+
+// writeResponse writes the HTTPResponse returned by a usecase
+func writeResponse(w http.ResponseWriter, r *http.Request, response *model.HTTPResponse) {
+	// Set response content type if specified
+	if response.ContentType != "" {
+		w.Header().Set("Content-Type", response.ContentType)
+	}
+	// Set status code
+	w.WriteHeader(response.StatusCode)
+	// Write response body if present
+	if len(response.Body) > 0 {
+		if _, err := w.Write(response.Body); err != nil {
+			slog.ErrorContext(r.Context(), "failed to write response", "error", err)
+		}
 	}
 }
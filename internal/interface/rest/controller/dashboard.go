@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
+)
+
+const (
+	// sessionCookieName holds the member signed in to the dashboard
+	sessionCookieName = "review_bot_session"
+	// signInCookieName holds the state and nonce of a sign-in in progress
+	signInCookieName = "review_bot_sign_in"
+	// signInTimeout is how long a sign-in may take on Slack's side
+	signInTimeout = 10 * time.Minute
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var dashboardTemplate = template.Must(template.New("dashboard.html").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15:04")
+	},
+	"age": func(l *i18n.Localizer, from, to time.Time) string {
+		d := to.Sub(from).Round(time.Minute)
+		if d < time.Hour {
+			return l.T("duration.minutes", int(d.Minutes()))
+		}
+		if d < 24*time.Hour {
+			return l.T("duration.hours_minutes", int(d.Hours()), int(d.Minutes())%60)
+		}
+		return l.T("duration.days_hours", int(d.Hours())/24, int(d.Hours())%24)
+	},
+	"status": func(l *i18n.Localizer, status model.ReviewStatus) string {
+		if id, ok := reviewStatusLabels[status]; ok {
+			return l.T(id)
+		}
+		return string(status)
+	},
+	"memberName": func(view *usecase.DashboardView, memberID model.MemberID) string {
+		if memberID == "" {
+			return ""
+		}
+		return view.MemberName(memberID)
+	},
+	"thread": func(view *usecase.DashboardView, review *model.Review) map[string]string {
+		thread := map[string]string{"Label": review.ChannelID + " " + review.ThreadTS}
+		if view.WorkspaceURL != "" {
+			thread["Permalink"] = model.ThreadPermalink(view.WorkspaceURL, review.ChannelID, review.ThreadTS)
+		}
+		return thread
+	},
+}).ParseFS(templateFS, "templates/dashboard.html"))
+
+// reviewStatusLabels are the messages labeling the review statuses on the dashboard
+var reviewStatusLabels = map[model.ReviewStatus]string{
+	model.ReviewStatusPending:          "dashboard.status.pending",
+	model.ReviewStatusAssigning:        "dashboard.status.assigning",
+	model.ReviewStatusAssigned:         "dashboard.status.assigned",
+	model.ReviewStatusChangesRequested: "dashboard.status.changes_requested",
+	model.ReviewStatusCompleted:        "dashboard.status.completed",
+	model.ReviewStatusApproved:         "dashboard.status.approved",
+	model.ReviewStatusMerged:           "dashboard.status.merged",
+	model.ReviewStatusClosed:           "dashboard.status.closed",
+}
+
+// signInState is kept in a cookie between sending the browser to Slack and its return
+type signInState struct {
+	State string `json:"state"`
+	Nonce string `json:"nonce"`
+}
+
+type identityContextKey struct{}
+
+// RequireSession sends browsers that have not signed in to Sign in with Slack
+func (c *Controller) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := c.sessionIdentity(r)
+		if !ok {
+			http.Redirect(w, r, "/dashboard/login", http.StatusFound)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity)))
+	})
+}
+
+// WithSession makes the member signed in with Slack, if any, available through SignedInMember
+func (c *Controller) WithSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := c.sessionIdentity(r); ok {
+			r = r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SignedInMember returns the member signed in with Slack who made the request, or an empty ID
+func SignedInMember(ctx context.Context) model.MemberID {
+	if identity, ok := ctx.Value(identityContextKey{}).(*model.SlackIdentity); ok {
+		return identity.MemberID
+	}
+	return ""
+}
+
+// sessionIdentity returns the member of the workspace signed in with the session cookie of the request
+func (c *Controller) sessionIdentity(r *http.Request) (*model.SlackIdentity, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil, false
+	}
+	var identity model.SlackIdentity
+	if err := c.sessions.decode(sessionCookieName, cookie.Value, &identity); err != nil {
+		return nil, false
+	}
+	if identity.MemberID == "" || identity.TeamID != c.sessionOptions.TeamID {
+		slog.WarnContext(r.Context(), "rejected session of an unknown member", "team_id", identity.TeamID, "member_id", identity.MemberID)
+		return nil, false
+	}
+	return &identity, true
+}
+
+func (c *Controller) HandleDashboard(w http.ResponseWriter, r *http.Request) {
+	identity, _ := r.Context().Value(identityContextKey{}).(*model.SlackIdentity)
+	l := c.dashboard.Localizer(r.Context(), SignedInMember(r.Context()))
+	view, err := c.dashboard.GetDashboard(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to get dashboard", "error", err)
+		http.Error(w, l.T("dashboard.error.unavailable"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	err = dashboardTemplate.Execute(w, struct {
+		Identity *model.SlackIdentity
+		View     *usecase.DashboardView
+		L        *i18n.Localizer
+	}{identity, view, l})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to render dashboard", "error", err)
+	}
+}
+
+// HandleDashboardLogin starts Sign in with Slack
+func (c *Controller) HandleDashboardLogin(w http.ResponseWriter, r *http.Request) {
+	state := signInState{State: randomToken(), Nonce: randomToken()}
+	value, err := c.sessions.encode(signInCookieName, state, time.Now().Add(signInTimeout))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode sign-in state", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	c.setCookie(w, signInCookieName, value, signInTimeout)
+	http.Redirect(w, r, c.dashboard.AuthorizationURL(state.State, state.Nonce), http.StatusFound)
+}
+
+// HandleDashboardCallback completes Sign in with Slack when Slack sends the browser back.
+// Nobody is signed in yet, so errors are written in the language of the workspace.
+func (c *Controller) HandleDashboardCallback(w http.ResponseWriter, r *http.Request) {
+	l := c.dashboard.Localizer(r.Context(), "")
+	var state signInState
+	cookie, err := r.Cookie(signInCookieName)
+	if err == nil {
+		err = c.sessions.decode(signInCookieName, cookie.Value, &state)
+	}
+	c.setCookie(w, signInCookieName, "", -1)
+	params := r.URL.Query()
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.State), []byte(params.Get("state"))) != 1 {
+		slog.WarnContext(r.Context(), "rejected sign-in callback with an unknown state")
+		http.Error(w, l.T("dashboard.error.sign_in_again"), http.StatusBadRequest)
+		return
+	}
+	if reason := params.Get("error"); reason != "" {
+		slog.InfoContext(r.Context(), "sign-in was not completed", "error", reason)
+		http.Error(w, l.T("dashboard.error.sign_in_cancelled"), http.StatusForbidden)
+		return
+	}
+	identity, err := c.dashboard.SignIn(r.Context(), params.Get("code"), state.Nonce)
+	if errors.Is(err, usecase.ErrOtherWorkspace) {
+		http.Error(w, l.T("dashboard.error.other_workspace"), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to sign in", "error", err)
+		http.Error(w, l.T("dashboard.error.sign_in_failed"), http.StatusBadGateway)
+		return
+	}
+	value, err := c.sessions.encode(sessionCookieName, identity, time.Now().Add(c.sessionOptions.TTL))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode session", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	c.setCookie(w, sessionCookieName, value, c.sessionOptions.TTL)
+	http.Redirect(w, r, "/dashboard", http.StatusFound)
+}
+
+func (c *Controller) HandleDashboardLogout(w http.ResponseWriter, r *http.Request) {
+	// Written in the language of the member signing out
+	l := c.dashboard.Localizer(r.Context(), SignedInMember(r.Context()))
+	c.setCookie(w, sessionCookieName, "", -1)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write([]byte(l.T("dashboard.signed_out"))); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", "error", err)
+	}
+}
+
+// setCookie sets a cookie for the dashboard and the API; a negative maxAge deletes it
+func (c *Controller) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   c.sessionOptions.Secure,
+		HttpOnly: true,
+		// Lax lets the cookies through on the redirect back from Slack
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
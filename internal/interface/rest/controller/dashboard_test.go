@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"github.com/himura467/slack-review-request-bot/internal/usecase"
+)
+
+// fakeDashboard signs in nobody and shows the given view in the given language
+type fakeDashboard struct {
+	catalog  *i18n.Catalog
+	language i18n.Language
+	view     *usecase.DashboardView
+}
+
+func (fakeDashboard) AuthorizationURL(state, nonce string) string {
+	return "https://slack.example.com/openid/connect/authorize?state=" + state
+}
+
+func (fakeDashboard) SignIn(context.Context, string, string) (*model.SlackIdentity, error) {
+	return nil, usecase.ErrOtherWorkspace
+}
+
+func (f fakeDashboard) GetDashboard(context.Context) (*usecase.DashboardView, error) {
+	return f.view, nil
+}
+
+func (f fakeDashboard) Localizer(context.Context, model.MemberID) *i18n.Localizer {
+	return f.catalog.Localizer(f.language)
+}
+
+func newTestController() *Controller {
+	return newTestControllerWith(fakeDashboard{})
+}
+
+func newTestControllerWith(dashboard fakeDashboard) *Controller {
+	return NewController(nil, nil, nil, nil, nil, dashboard, nil, nil, nil, SessionOptions{
+		Secret: []byte("session-secret"),
+		TTL:    time.Hour,
+		TeamID: "T1",
+	})
+}
+
+// requestWithSession requests the dashboard behind RequireSession with the given session cookie
+func requestWithSession(c *Controller, session string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	r.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session})
+	w := httptest.NewRecorder()
+	c.RequireSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(SignedInMember(r.Context())))
+	})).ServeHTTP(w, r)
+	return w
+}
+
+func TestRequireSessionRejectsSignInCookie(t *testing.T) {
+	c := newTestController()
+	w := httptest.NewRecorder()
+	c.HandleDashboardLogin(w, httptest.NewRequest(http.MethodGet, "/dashboard/login", nil))
+	var signIn string
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == signInCookieName {
+			signIn = cookie.Value
+		}
+	}
+	if signIn == "" {
+		t.Fatal("HandleDashboardLogin() set no sign-in cookie")
+	}
+
+	if got := requestWithSession(c, signIn); got.Code != http.StatusFound {
+		t.Fatalf("status with the sign-in cookie as session = %d, want a redirect to sign in", got.Code)
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	c := newTestController()
+	tests := []struct {
+		name     string
+		identity model.SlackIdentity
+		wantCode int
+	}{
+		{name: "member of the workspace", identity: model.SlackIdentity{MemberID: "U1", TeamID: "T1"}, wantCode: http.StatusOK},
+		{name: "member of another workspace", identity: model.SlackIdentity{MemberID: "U1", TeamID: "T2"}, wantCode: http.StatusFound},
+		{name: "no member", identity: model.SlackIdentity{TeamID: "T1"}, wantCode: http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := c.sessions.encode(sessionCookieName, tt.identity, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := requestWithSession(c, session)
+			if got.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", got.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK && got.Body.String() != string(tt.identity.MemberID) {
+				t.Errorf("signed-in member = %q, want %s", got.Body.String(), tt.identity.MemberID)
+			}
+		})
+	}
+}
+
+func TestHandleDashboardIsLocalized(t *testing.T) {
+	catalog, err := i18n.NewCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	dashboard := model.NewDashboard(now, 7*24*time.Hour)
+	dashboard.Add(&model.Review{ID: "C1:1.0", ChannelID: "C1", ThreadTS: "1.0", Status: model.ReviewStatusPending, CreatedAt: now.Add(-26 * time.Hour)})
+	dashboard.Finish()
+	view := &usecase.DashboardView{Dashboard: dashboard, MemberName: func(memberID model.MemberID) string { return string(memberID) }}
+	tests := []struct {
+		language i18n.Language
+		want     []string
+	}{
+		{language: i18n.English, want: []string{`<html lang="en">`, "Open review (1)", "No reviewer yet", "1d 2h", "Signed in as Alice"}},
+		{language: i18n.Japanese, want: []string{`<html lang="ja">`, "未完了のレビュー (1件)", "レビュワー未定", "1日2時間", "Alice としてサインイン中"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.language), func(t *testing.T) {
+			c := newTestControllerWith(fakeDashboard{catalog: catalog, language: tt.language, view: view})
+			r := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+			identity := &model.SlackIdentity{MemberID: "U1", TeamID: "T1", Name: "Alice"}
+			r = r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+			w := httptest.NewRecorder()
+			c.HandleDashboard(w, r)
+
+			body := w.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("dashboard does not contain %q", want)
+				}
+			}
+		})
+	}
+}
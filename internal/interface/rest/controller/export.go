@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// exportWriteTimeout replaces the server's write timeout for exports, which may take longer than other requests
+const exportWriteTimeout = 5 * time.Minute
+
+// HandleExportReviews streams the reviews requested between from and to (RFC 3339) as csv, json or ndjson
+func (c *Controller) HandleExportReviews(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	format, err := model.ParseExportFormat(params.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := model.ExportQuery{Format: format}
+	for name, dst := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, name+" must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		*dst = t
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		slog.WarnContext(r.Context(), "failed to extend write deadline for export", "error", err)
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="reviews.`+string(format)+`"`)
+	w.WriteHeader(http.StatusOK)
+	if err := c.export.ExportReviews(r.Context(), query, &flushWriter{w: w, rc: rc}); err != nil {
+		// The status has been sent already, so the client only sees a truncated body
+		slog.ErrorContext(r.Context(), "failed to export reviews", "error", err)
+	}
+}
+
+// flushWriter sends every write to the client right away instead of buffering the whole response
+type flushWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err := f.rc.Flush(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"net/http"
+)
+
+func (c *Controller) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, c.health.Live(r.Context()))
+}
+
+func (c *Controller) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, c.health.Ready(r.Context()))
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+const (
+	// maxReviewRequestWait caps how long a lookup may wait for the review request to change
+	maxReviewRequestWait = 2 * time.Minute
+	// reviewRequestWaitMargin is added to the wait for answering after it, beyond the server's write timeout
+	reviewRequestWaitMargin = 10 * time.Second
+)
+
+// HandleCreateReviewRequest requests a review given as {"channel": ..., "thread_ts" or "text": ..., "mode": ...,
+// "reviewers": [...], "requester_id": ..., "external_ref": ...} and assigns a reviewer
+func (c *Controller) HandleCreateReviewRequest(w http.ResponseWriter, r *http.Request) {
+	var request model.ReviewRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	writeResponse(w, r, c.reviewRequest.CreateReviewRequest(r.Context(), &request))
+}
+
+// HandleGetReviewRequest returns the review request with the ID in the path
+func (c *Controller) HandleGetReviewRequest(w http.ResponseWriter, r *http.Request) {
+	c.getReviewRequest(w, r, model.ReviewRequestQuery{ID: chi.URLParam(r, "id")})
+}
+
+// HandleFindReviewRequest returns the review request in the thread linked to by thread,
+// or the latest one about external_ref
+func (c *Controller) HandleFindReviewRequest(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	c.getReviewRequest(w, r, model.ReviewRequestQuery{ThreadLink: params.Get("thread"), ExternalRef: params.Get("external_ref")})
+}
+
+// getReviewRequest answers the query, waiting up to wait (e.g. 30s) for the review request to change from version
+func (c *Controller) getReviewRequest(w http.ResponseWriter, r *http.Request, query model.ReviewRequestQuery) {
+	params := r.URL.Query()
+	if v := params.Get("wait"); v != "" {
+		wait, err := time.ParseDuration(v)
+		if err != nil || wait < 0 || wait > maxReviewRequestWait {
+			http.Error(w, "wait must be a duration of at most "+maxReviewRequestWait.String(), http.StatusBadRequest)
+			return
+		}
+		query.Wait = wait
+	}
+	if v := params.Get("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 0 {
+			http.Error(w, "version must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		query.Version = version
+	}
+	if query.Wait > 0 {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(query.Wait + reviewRequestWaitMargin)); err != nil {
+			slog.WarnContext(r.Context(), "failed to extend write deadline for waiting", "error", err)
+		}
+	}
+	writeResponse(w, r, c.reviewRequest.GetReviewRequest(r.Context(), query))
+}
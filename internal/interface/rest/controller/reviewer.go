@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+func (c *Controller) HandleListReviewers(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, c.reviewer.ListReviewers(r.Context()))
+}
+
+// HandleCreateReviewer adds the reviewer given as
+// {"display_name": ..., "member_id": ..., "github_login": ..., "gitlab_username": ...}
+func (c *Controller) HandleCreateReviewer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		model.Member
+		GitHubLogin    string `json:"github_login"`
+		GitLabUsername string `json:"gitlab_username"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	logins := map[model.CodeHost]string{
+		model.CodeHostGitHub: body.GitHubLogin,
+		model.CodeHostGitLab: body.GitLabUsername,
+	}
+	writeResponse(w, r, c.reviewer.CreateReviewer(r.Context(), SignedInMember(r.Context()), body.Member, logins))
+}
+
+// HandleUpdateReviewer changes the display name or logins given as
+// {"display_name": ..., "github_login": ..., "gitlab_username": ...}; the fields left out stay as they are
+func (c *Controller) HandleUpdateReviewer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DisplayName    *string `json:"display_name"`
+		GitHubLogin    *string `json:"github_login"`
+		GitLabUsername *string `json:"gitlab_username"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	logins := map[model.CodeHost]*string{
+		model.CodeHostGitHub: body.GitHubLogin,
+		model.CodeHostGitLab: body.GitLabUsername,
+	}
+	writeResponse(w, r, c.reviewer.UpdateReviewer(r.Context(), SignedInMember(r.Context()), memberIDParam(r), body.DisplayName, logins))
+}
+
+func (c *Controller) HandleDeleteReviewer(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, c.reviewer.DeleteReviewer(r.Context(), SignedInMember(r.Context()), memberIDParam(r)))
+}
+
+// HandlePauseReviewer pauses the reviewer until resumed, or until the RFC 3339 time given as {"until": ...}
+func (c *Controller) HandlePauseReviewer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Until *time.Time `json:"until"`
+	}
+	if r.ContentLength != 0 && !decodeJSON(w, r, &body) {
+		return
+	}
+	var until time.Time
+	if body.Until != nil {
+		until = *body.Until
+	}
+	writeResponse(w, r, c.reviewer.PauseReviewer(r.Context(), SignedInMember(r.Context()), memberIDParam(r), until))
+}
+
+func (c *Controller) HandleResumeReviewer(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, c.reviewer.ResumeReviewer(r.Context(), SignedInMember(r.Context()), memberIDParam(r)))
+}
+
+func memberIDParam(r *http.Request) model.MemberID {
+	return model.MemberID(chi.URLParam(r, "memberID"))
+}
+
+// decodeJSON decodes the JSON body of r into v, answering the request itself when that fails.
+// Requiring the JSON media type keeps HTML forms on other sites from posting with a signed-in browser.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		slog.WarnContext(r.Context(), "request body too large", "limit", maxBytesErr.Limit)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return false
+	case errors.Is(err, io.EOF):
+		http.Error(w, "request body is empty", http.StatusBadRequest)
+		return false
+	case err != nil:
+		http.Error(w, "malformed request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
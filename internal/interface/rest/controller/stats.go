@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+)
+
+// HandleGetStats returns the review statistics of a period given either as period (e.g. 7d) ending now,
+// or as from and optionally to in RFC 3339
+func (c *Controller) HandleGetStats(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	now := time.Now()
+	period, err := model.ParseStatsPeriod(params.Get("period"), now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from := params.Get("from"); from != "" {
+		if period.From, err = time.Parse(time.RFC3339, from); err != nil {
+			http.Error(w, "from must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		period.To = now
+	}
+	if to := params.Get("to"); to != "" {
+		if period.To, err = time.Parse(time.RFC3339, to); err != nil {
+			http.Error(w, "to must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	writeResponse(w, r, c.stats.GetStats(r.Context(), period))
+}
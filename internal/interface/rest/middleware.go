@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/himura467/slack-review-request-bot/internal/interface/rest/controller"
+	"github.com/himura467/slack-review-request-bot/internal/logging"
+)
+
+// requestID attaches the request ID assigned by middleware.RequestID to the log lines of the request
+// and returns it to the caller
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := middleware.GetReqID(r.Context())
+		w.Header().Set(middleware.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// accessLog logs the method, path, status and latency of every request
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || r.URL.Path == "/metrics":
+				// Probes and scrapes would drown out everything else
+				level = slog.LevelDebug
+			}
+			slog.Log(
+				r.Context(),
+				level,
+				"request completed",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"latency_ms", float64(time.Since(start).Microseconds())/1000,
+				"bytes", ww.BytesWritten(),
+			)
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// recoverPanic turns a panicking handler into a 500 instead of a dropped connection
+func recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// The handler deliberately aborted the response
+				panic(rec)
+			}
+			slog.ErrorContext(r.Context(), "request panicked", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireScope rejects requests that do not carry one of the tokens with the scope as a bearer token.
+// Without any tokens every request is rejected.
+func requireScope(tokens []APIToken, scope string) func(http.Handler) http.Handler {
+	return requireScopeOrAdmin(tokens, scope, nil)
+}
+
+// requireScopeOrAdmin is requireScope that also accepts the requests of the members signed in with Slack
+// through controller.Controller.WithSession
+func requireScopeOrAdmin(tokens []APIToken, scope string, adminMemberIDs []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			memberID := controller.SignedInMember(r.Context())
+			if memberID != "" && slices.Contains(adminMemberIDs, string(memberID)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			bearer, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			token, ok := findAPIToken(tokens, bearer)
+			switch {
+			case !ok && memberID != "":
+				slog.WarnContext(r.Context(), "rejected API request of a member who is not an administrator", "member_id", memberID)
+				w.WriteHeader(http.StatusForbidden)
+			case !ok:
+				slog.WarnContext(r.Context(), "rejected API request without a valid key")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="review-bot"`)
+				w.WriteHeader(http.StatusUnauthorized)
+			case !token.HasScope(scope):
+				slog.WarnContext(r.Context(), "rejected API request with a token lacking the scope", "api_token", token.Name, "scope", scope)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="review-bot", error="insufficient_scope", scope="`+scope+`"`)
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				next.ServeHTTP(w, r.WithContext(logging.With(r.Context(), slog.String("api_token", token.Name))))
+			}
+		})
+	}
+}
+
+// findAPIToken returns the token whose value is bearer, comparing in constant time
+func findAPIToken(tokens []APIToken, bearer string) (APIToken, bool) {
+	var found APIToken
+	ok := false
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Token), []byte(bearer)) == 1 {
+			found, ok = token, true
+		}
+	}
+	return found, bearer != "" && ok
+}
@@ -1,33 +1,149 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"os"
+	"slices"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/himura467/slack-review-request-bot/internal/interface/rest/controller"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// Scopes of the API tokens; a token with scopeAll may do everything
+const (
+	scopeAll                 = "*"
+	scopeAuditRead           = "audit:read"
+	scopeStatsRead           = "stats:read"
+	scopeReviewsRead         = "reviews:read"
+	scopeReviewersWrite      = "reviewers:write"
+	scopeReviewRequestsRead  = "review_requests:read"
+	scopeReviewRequestsWrite = "review_requests:write"
+)
+
+// APIToken is a bearer token accepted under /api for the requests within its scopes
+type APIToken struct {
+	Name   string
+	Token  string
+	Scopes []string
+}
+
+// HasScope reports whether the token may make requests of the scope
+func (t APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope) || slices.Contains(t.Scopes, scopeAll)
+}
+
+// MetricsHandler serves the metrics of the bot to Prometheus
+type MetricsHandler http.Handler
+
+// ServerOptions configures the HTTP server
+type ServerOptions struct {
+	Port         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	MaxBodyBytes int64
+	// APITokens are the bearer tokens accepted under /api
+	APITokens []APIToken
+	// AdminMemberIDs are the members who may manage the reviewers under /api after signing in with Slack
+	AdminMemberIDs []string
+	// DashboardEnabled serves the dashboard under /dashboard
+	DashboardEnabled bool
+}
+
 type Server struct {
 	router     *chi.Mux
 	controller *controller.Controller
+	metrics    MetricsHandler
+	options    ServerOptions
+	httpServer *http.Server
 }
 
-func NewServer(controller *controller.Controller) *Server {
+func NewServer(controller *controller.Controller, metrics MetricsHandler, options ServerOptions) *Server {
+	router := chi.NewRouter()
+	// Start a span for every request, continuing the trace of the caller if any
+	router.Use(otelhttp.NewMiddleware(
+		"http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			switch r.URL.Path {
+			case "/metrics", "/healthz", "/readyz":
+				return false
+			default:
+				return true
+			}
+		}),
+	))
+	router.Use(
+		middleware.RequestID,
+		requestID,
+		accessLog,
+		recoverPanic,
+		middleware.RequestSize(options.MaxBodyBytes),
+	)
 	return &Server{
-		router:     chi.NewRouter(),
+		router:     router,
 		controller: controller,
+		metrics:    metrics,
+		options:    options,
+		httpServer: &http.Server{
+			Addr:              ":" + options.Port,
+			Handler:           router,
+			ReadHeaderTimeout: options.ReadTimeout,
+			ReadTimeout:       options.ReadTimeout,
+			WriteTimeout:      options.WriteTimeout,
+		},
 	}
 }
 
+// Run serves requests until Shutdown is called
 func (s *Server) Run() error {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
 	s.router.Post("/slack/events", s.controller.HandleEvent)
 	s.router.Post("/slack/interactions", s.controller.HandleInteraction)
+	s.router.Post("/slack/commands", s.controller.HandleCommand)
+	s.router.Post("/github/webhook", s.controller.HandleGitHubWebhook)
+	s.router.Post("/gitlab/webhook", s.controller.HandleGitLabWebhook)
+	s.router.Method(http.MethodGet, "/metrics", s.metrics)
+	s.router.Get("/healthz", s.controller.HandleHealthz)
+	s.router.Get("/readyz", s.controller.HandleReadyz)
+	s.router.Route("/api", func(r chi.Router) {
+		r.With(requireScope(s.options.APITokens, scopeReviewsRead)).Get("/reviews/export", s.controller.HandleExportReviews)
+		r.With(requireScope(s.options.APITokens, scopeAuditRead)).Get("/v1/audit", s.controller.HandleListAuditEvents)
+		r.With(requireScope(s.options.APITokens, scopeStatsRead)).Get("/v1/stats", s.controller.HandleGetStats)
+		r.With(requireScope(s.options.APITokens, scopeReviewRequestsWrite)).Post("/v1/review-requests", s.controller.HandleCreateReviewRequest)
+		r.With(requireScope(s.options.APITokens, scopeReviewRequestsRead)).Get("/v1/review-requests", s.controller.HandleFindReviewRequest)
+		r.With(requireScope(s.options.APITokens, scopeReviewRequestsRead)).Get("/v1/review-requests/{id}", s.controller.HandleGetReviewRequest)
+		// Administrators signed in with Slack may manage the reviewers as well
+		r.Group(func(r chi.Router) {
+			r.Use(s.controller.WithSession, requireScopeOrAdmin(s.options.APITokens, scopeReviewersWrite, s.options.AdminMemberIDs))
+			r.Get("/v1/reviewers", s.controller.HandleListReviewers)
+			r.Post("/v1/reviewers", s.controller.HandleCreateReviewer)
+			r.Put("/v1/reviewers/{memberID}", s.controller.HandleUpdateReviewer)
+			r.Delete("/v1/reviewers/{memberID}", s.controller.HandleDeleteReviewer)
+			r.Post("/v1/reviewers/{memberID}/pause", s.controller.HandlePauseReviewer)
+			r.Delete("/v1/reviewers/{memberID}/pause", s.controller.HandleResumeReviewer)
+		})
+	})
+	if s.options.DashboardEnabled {
+		s.router.Route("/dashboard", func(r chi.Router) {
+			r.Get("/login", s.controller.HandleDashboardLogin)
+			r.Get("/callback", s.controller.HandleDashboardCallback)
+			r.With(s.controller.WithSession).Post("/logout", s.controller.HandleDashboardLogout)
+			r.With(s.controller.RequireSession).Get("/", s.controller.HandleDashboard)
+		})
+	}
+
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
 
-	return http.ListenAndServe(":"+port, s.router)
+// Shutdown stops accepting connections and waits for in-flight requests to complete
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
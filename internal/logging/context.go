@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type attrsKey struct{}
+
+// With returns a context whose log lines carry attrs in addition to those already attached to ctx.
+// Log with the *Context variants of slog, e.g. slog.ErrorContext, for the attributes to be added.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	current := Attrs(ctx)
+	merged := make([]slog.Attr, 0, len(current)+len(attrs))
+	merged = append(merged, current...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// Attrs returns the attributes attached to ctx
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// WithRequestID attaches the ID of the HTTP request being handled
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return With(ctx, slog.String("request_id", requestID))
+}
+
+// WithSlack attaches the Slack workspace, channel and user an event came from, skipping the unknown ones
+func WithSlack(ctx context.Context, teamID, channelID, userID string) context.Context {
+	var attrs []slog.Attr
+	if teamID != "" {
+		attrs = append(attrs, slog.String("slack_team", teamID))
+	}
+	if channelID != "" {
+		attrs = append(attrs, slog.String("slack_channel", channelID))
+	}
+	if userID != "" {
+		attrs = append(attrs, slog.String("slack_user", userID))
+	}
+	if len(attrs) == 0 {
+		return ctx
+	}
+	return With(ctx, attrs...)
+}
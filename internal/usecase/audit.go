@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+type AuditUsecase interface {
+	// ListAuditEvents returns the audit events selected by the query as JSON, oldest first
+	ListAuditEvents(ctx context.Context, query model.AuditQuery) *model.HTTPResponse
+}
+
+type AuditUsecaseImpl struct {
+	auditRepo repository.AuditRepository
+}
+
+var _ AuditUsecase = (*AuditUsecaseImpl)(nil)
+
+func NewAuditUsecase(auditRepo repository.AuditRepository) *AuditUsecaseImpl {
+	return &AuditUsecaseImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+func (u *AuditUsecaseImpl) ListAuditEvents(ctx context.Context, query model.AuditQuery) *model.HTTPResponse {
+	events, err := u.auditRepo.List(query)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list audit events", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	if events == nil {
+		events = []*model.AuditEvent{}
+	}
+	body, err := json.Marshal(struct {
+		Events []*model.AuditEvent `json:"events"`
+	}{Events: events})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal audit events", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return model.NewJSONResponse(http.StatusOK, body)
+}
+
+// recordAudit appends the event to the audit log.
+// A failure is logged but does not fail the action that is being audited.
+func (u *SlackUsecaseImpl) recordAudit(ctx context.Context, event *model.AuditEvent) {
+	appendAuditEvent(ctx, u.auditRepo, event)
+}
+
+// appendAuditEvent appends the event to the audit log, logging a failure
+func appendAuditEvent(ctx context.Context, auditRepo repository.AuditRepository, event *model.AuditEvent) {
+	if err := auditRepo.Append(event); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", "type", event.Type, "review_id", event.ReviewID, "error", err)
+	}
+}
+
+// mentionPattern matches user mentions such as <@U0123456> in message text
+var mentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+
+// parseMentionCommand returns the first word of the mention text without the mentions as the command,
+// and the remaining words as its arguments
+func parseMentionCommand(text string) (command string, args []string) {
+	fields := strings.Fields(mentionPattern.ReplaceAllString(text, " "))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), fields[1:]
+}
+
+// auditCommandTarget returns the thread whose audit log `@bot audit [thread link]` asks for.
+// Without a link it is the thread of the mention itself.
+// It reports false when the argument is not a thread link, in which case the mention is an ordinary review request.
+func auditCommandTarget(event *model.AppMentionEvent, args []string) (channelID, threadTS string, ok bool) {
+	switch len(args) {
+	case 0:
+		return event.ChannelID, event.ThreadTS, true
+	case 1:
+		return model.ParseThreadLink(args[0])
+	default:
+		return "", "", false
+	}
+}
+
+// handleAuditCommand posts the audit log of a review request in the thread of the mention
+func (u *SlackUsecaseImpl) handleAuditCommand(ctx context.Context, event *model.AppMentionEvent, channelID, threadTS string) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.handleAuditCommand")
+	defer span.End()
+	events, err := u.auditRepo.List(model.ReviewAuditQuery(channelID, threadTS))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list audit events", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	text := u.formatAuditLog(u.localizer(ctx, event.ChannelID, event.MemberID), events, u.allReviewers(ctx))
+	message := model.NewMessage(event.ChannelID, text, nil, false, event.ThreadTS)
+	if _, err := u.slackRepo.PostMessage(ctx, message); err != nil {
+		slog.ErrorContext(ctx, "failed to post audit log", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return model.NewStatusResponse(http.StatusOK)
+}
+
+// auditEventLabels are the messages describing the audit event types in Slack
+var auditEventLabels = map[model.AuditEventType]string{
+	model.AuditEventSelectionPosted:    "audit.selection_posted",
+	model.AuditEventRequested:          "audit.requested",
+	model.AuditEventClicked:            "audit.clicked",
+	model.AuditEventAssigned:           "audit.assigned",
+	model.AuditEventReassigned:         "audit.reassigned",
+	model.AuditEventCompleted:          "audit.completed",
+	model.AuditEventPullRequestUpdated: "audit.pull_request_updated",
+	model.AuditEventAdminChanged:       "audit.admin_changed",
+}
+
+// formatAuditLog formats the audit events as a Slack message, one line per event.
+// Members are written by their names among the reviewers rather than mentioned so that reading the log does not notify them.
+func (u *SlackUsecaseImpl) formatAuditLog(l *i18n.Localizer, events []*model.AuditEvent, reviewers model.ReviewerMap) string {
+	if len(events) == 0 {
+		return l.T("audit.empty")
+	}
+	var b strings.Builder
+	b.WriteString(l.T("audit.title"))
+	for _, event := range events {
+		label := string(event.Type)
+		if id, ok := auditEventLabels[event.Type]; ok {
+			label = l.T(id)
+		}
+		// Slack shows the time in the time zone of each reader
+		fmt.Fprintf(&b, "\n• <!date^%d^{date_short_pretty} {time_secs}|%s> %s", event.At.Unix(), event.At.Format("2006-01-02 15:04:05 MST"), label)
+		if event.ActorID != "" {
+			b.WriteString(" " + l.T("audit.by", reviewers.NameOf(event.ActorID)))
+		} else if event.ExternalActor != "" {
+			b.WriteString(" " + l.T("audit.by_on", event.ExternalActor, event.ExternalHost.Name()))
+		}
+		if event.Mode != "" {
+			fmt.Fprintf(&b, " (%s)", event.Mode)
+		}
+		if event.PreviousReviewer != nil {
+			fmt.Fprintf(&b, " %s →", event.PreviousReviewer.DisplayName)
+		}
+		if event.Reviewer != nil {
+			fmt.Fprintf(&b, " %s", event.Reviewer.DisplayName)
+		}
+		if len(event.Candidates) > 0 {
+			b.WriteString(" " + l.Plural("audit.candidates", len(event.Candidates), memberNames(reviewers, event.Candidates)))
+		}
+		if len(event.Excluded) > 0 {
+			b.WriteString(" " + l.Plural("audit.excluded", len(event.Excluded), memberNames(reviewers, event.Excluded)))
+		}
+		if event.Detail != "" {
+			fmt.Fprintf(&b, " _%s_", event.Detail)
+		}
+	}
+	return b.String()
+}
+
+// memberNames returns the names of the members among the reviewers separated by commas
+func memberNames(reviewers model.ReviewerMap, memberIDs []model.MemberID) string {
+	names := make([]string, len(memberIDs))
+	for i, memberID := range memberIDs {
+		names[i] = reviewers.NameOf(memberID)
+	}
+	return strings.Join(names, ", ")
+}
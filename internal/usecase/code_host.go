@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CodeHostUsecase interface {
+	// HandleWebhook moves the reviews of the pull request a webhook delivery of the code host is about along
+	// and tells their threads
+	HandleWebhook(ctx context.Context, host model.CodeHost, r *model.HTTPRequest) *model.HTTPResponse
+}
+
+var _ CodeHostUsecase = (*SlackUsecaseImpl)(nil)
+
+func (u *SlackUsecaseImpl) HandleWebhook(ctx context.Context, host model.CodeHost, r *model.HTTPRequest) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleWebhook", trace.WithAttributes(
+		attribute.String("code_host", string(host)),
+	))
+	defer span.End()
+	codeHost, ok := u.codeHosts.Get(host)
+	if !ok {
+		return model.NewStatusResponse(http.StatusNotFound)
+	}
+	if err := codeHost.VerifyWebhook(r); err != nil {
+		if errors.Is(err, repository.ErrCodeHostDisabled) {
+			return model.NewStatusResponse(http.StatusNotFound)
+		}
+		slog.ErrorContext(ctx, "failed to verify webhook", "host", host, "error", err)
+		return model.NewStatusResponse(http.StatusUnauthorized)
+	}
+	event, err := codeHost.ParseWebhook(r)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to parse webhook", "host", host, "error", err)
+		return model.NewStatusResponse(http.StatusBadRequest)
+	}
+	if event == nil {
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	span.SetAttributes(
+		attribute.String("code_host.pull_request", event.PullRequestRef.String()),
+		attribute.String("code_host.action", string(event.Action)),
+		attribute.String("code_host.delivery_id", event.DeliveryID),
+	)
+
+	// The same pull request may have been requested for review in several threads
+	var reviewIDs []string
+	err = u.reviewRepo.Each(func(review *model.Review) error {
+		if ref, _, ok := u.codeHosts.ParsePullRequestURL(review.ExternalRef); ok && ref.SameAs(event.PullRequestRef) {
+			reviewIDs = append(reviewIDs, review.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find reviews of pull request", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	for _, id := range reviewIDs {
+		if err := u.applyPullRequestEvent(ctx, id, event); err != nil {
+			// Code hosts do not redeliver failed deliveries by themselves, so carry on with the other reviews
+			slog.ErrorContext(ctx, "failed to update review", "review_id", id, "error", err)
+		}
+	}
+	slog.InfoContext(ctx, "handled webhook", "host", host, "pull_request", event.PullRequestRef.String(), "action", event.Action, "reviews", len(reviewIDs))
+	return model.NewStatusResponse(http.StatusOK)
+}
+
+// applyPullRequestEvent moves the review along with its pull request and tells its thread
+func (u *SlackUsecaseImpl) applyPullRequestEvent(ctx context.Context, reviewID string, event *model.PullRequestEvent) error {
+	current, err := u.reviewRepo.Get(reviewID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	review, changed, err := u.updateReview(
+		current.ChannelID,
+		current.ThreadTS,
+		func() *model.Review {
+			return current
+		},
+		func(review *model.Review) bool {
+			return review.ApplyPullRequestEvent(event.Action, now)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		// Redeliveries and events that do not apply to the review leave it as it is
+		return nil
+	}
+	audit := model.NewAuditEvent(model.AuditEventPullRequestUpdated, review.ChannelID, review.ThreadTS, "", now)
+	audit.ExternalActor = event.Sender
+	audit.ExternalHost = event.Host
+	audit.Detail = string(event.Action)
+	// Reviewers are known in Slack by their logins on the code host
+	sender := event.Sender
+	if roster, err := u.reviewerRepo.GetRoster(); err != nil {
+		slog.WarnContext(ctx, "failed to get reviewer roster", "error", err)
+	} else if reviewer, ok := roster.FindByLogin(event.Host, event.Sender); ok {
+		sender = reviewer.DisplayName
+		audit.ActorID = reviewer.MemberID
+		if event.Action == model.PullRequestActionApproved {
+			audit.Reviewer = &reviewer.Member
+		}
+	}
+	u.recordAudit(ctx, audit)
+
+	text := pullRequestUpdateText(u.localizer(ctx, review.ChannelID, review.RequesterID), review, event, sender)
+	message := model.NewMessage(review.ChannelID, text, nil, false, review.ThreadTS)
+	if _, err := u.slackRepo.PostMessage(ctx, message); err != nil {
+		// The review has moved along anyway
+		slog.ErrorContext(ctx, "failed to post pull request update", "review_id", review.ID, "error", err)
+	}
+	return nil
+}
+
+// pullRequestUpdateText tells the thread of the review what happened to its pull request,
+// mentioning the requester when it is their turn
+func pullRequestUpdateText(l *i18n.Localizer, review *model.Review, event *model.PullRequestEvent, sender string) string {
+	link := "<" + event.Link + "|" + event.PullRequestRef.String() + ">"
+	var text string
+	switch event.Action {
+	case model.PullRequestActionApproved:
+		text = l.T("pull_request.approved", sender, link)
+	case model.PullRequestActionChangesRequested:
+		text = l.T("pull_request.changes_requested", sender, link)
+	case model.PullRequestActionMerged:
+		return l.T("pull_request.merged", link)
+	default:
+		return l.T("pull_request.closed", link)
+	}
+	if review.RequesterID != "" {
+		text = "<@" + string(review.RequesterID) + ">\n" + text
+	}
+	return text
+}
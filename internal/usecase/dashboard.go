@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// dashboardRecentPeriod is how far back the dashboard lists completed reviews
+const dashboardRecentPeriod = 7 * 24 * time.Hour
+
+// ErrOtherWorkspace is returned when a member of another workspace tries to sign in
+var ErrOtherWorkspace = errors.New("member of another workspace")
+
+type DashboardUsecase interface {
+	// AuthorizationURL returns where to send the browser to sign in with Slack
+	AuthorizationURL(state, nonce string) string
+	// SignIn completes signing in with the authorization code, accepting only members of the workspace
+	SignIn(ctx context.Context, code, nonce string) (*model.SlackIdentity, error)
+	// GetDashboard returns the overview of the review requests
+	GetDashboard(ctx context.Context) (*DashboardView, error)
+	// Localizer returns the localizer of the dashboard for the member signed in, or for nobody with an empty ID
+	Localizer(ctx context.Context, memberID model.MemberID) *i18n.Localizer
+}
+
+// DashboardUsecaseOptions configures DashboardUsecaseImpl
+type DashboardUsecaseOptions struct {
+	// TeamID is the workspace whose members may sign in
+	TeamID string
+	// Language is the language of the workspace
+	Language i18n.Language
+	// UserLocale shows the dashboard in the language of the Slack locale of the member signed in
+	UserLocale bool
+}
+
+// DashboardView is the dashboard with what is needed to show it
+type DashboardView struct {
+	*model.Dashboard
+	// WorkspaceURL links the reviews to their threads; empty if unknown
+	WorkspaceURL string
+	// MemberName returns the name of a member for display
+	MemberName func(memberID model.MemberID) string
+}
+
+type DashboardUsecaseImpl struct {
+	reviewRepo   repository.ReviewRepository
+	slackRepo    repository.SlackRepository
+	signInRepo   repository.SignInRepository
+	reviewerRepo repository.ReviewerRepository
+	catalog      *i18n.Catalog
+	options      DashboardUsecaseOptions
+}
+
+var _ DashboardUsecase = (*DashboardUsecaseImpl)(nil)
+
+func NewDashboardUsecase(
+	reviewRepo repository.ReviewRepository,
+	slackRepo repository.SlackRepository,
+	signInRepo repository.SignInRepository,
+	reviewerRepo repository.ReviewerRepository,
+	catalog *i18n.Catalog,
+	options DashboardUsecaseOptions,
+) *DashboardUsecaseImpl {
+	return &DashboardUsecaseImpl{
+		reviewRepo:   reviewRepo,
+		slackRepo:    slackRepo,
+		signInRepo:   signInRepo,
+		reviewerRepo: reviewerRepo,
+		catalog:      catalog,
+		options:      options,
+	}
+}
+
+func (u *DashboardUsecaseImpl) AuthorizationURL(state, nonce string) string {
+	return u.signInRepo.AuthorizationURL(state, nonce)
+}
+
+func (u *DashboardUsecaseImpl) SignIn(ctx context.Context, code, nonce string) (*model.SlackIdentity, error) {
+	ctx, span := tracer.Start(ctx, "DashboardUsecase.SignIn")
+	defer span.End()
+	identity, err := u.signInRepo.Exchange(ctx, code, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if identity.TeamID != u.options.TeamID {
+		slog.WarnContext(ctx, "rejected sign-in from another workspace", "team_id", identity.TeamID, "member_id", identity.MemberID)
+		return nil, ErrOtherWorkspace
+	}
+	slog.InfoContext(ctx, "signed in to dashboard", "member_id", identity.MemberID)
+	return identity, nil
+}
+
+func (u *DashboardUsecaseImpl) GetDashboard(ctx context.Context) (*DashboardView, error) {
+	ctx, span := tracer.Start(ctx, "DashboardUsecase.GetDashboard")
+	defer span.End()
+	dashboard := model.NewDashboard(time.Now(), dashboardRecentPeriod)
+	err := u.reviewRepo.Each(func(review *model.Review) error {
+		dashboard.Add(review)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Finish()
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		return nil, err
+	}
+	workspaceURL, err := u.slackRepo.GetWorkspaceURL(ctx)
+	if err != nil {
+		// The dashboard is still useful without links to the threads
+		slog.WarnContext(ctx, "failed to get workspace URL", "error", err)
+	}
+	return &DashboardView{
+		Dashboard:    dashboard,
+		WorkspaceURL: workspaceURL,
+		MemberName:   roster.ReviewerMap().NameOf,
+	}, nil
+}
+
+func (u *DashboardUsecaseImpl) Localizer(ctx context.Context, memberID model.MemberID) *i18n.Localizer {
+	if u.options.UserLocale && memberID != "" {
+		locale, err := u.slackRepo.GetUserLocale(ctx, memberID)
+		if err != nil {
+			// The language of the workspace will do
+			slog.WarnContext(ctx, "failed to get user locale", "member_id", memberID, "error", err)
+		} else if language, ok := i18n.ParseLanguage(locale); ok {
+			return u.catalog.Localizer(language)
+		}
+	}
+	return u.catalog.Localizer(u.options.Language)
+}
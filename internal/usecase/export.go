@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+type ExportUsecase interface {
+	// ExportReviews writes the reviews selected by the query to w in its format, one review at a time
+	ExportReviews(ctx context.Context, query model.ExportQuery, w io.Writer) error
+}
+
+type ExportUsecaseImpl struct {
+	reviewRepo repository.ReviewRepository
+	slackRepo  repository.SlackRepository
+}
+
+var _ ExportUsecase = (*ExportUsecaseImpl)(nil)
+
+func NewExportUsecase(reviewRepo repository.ReviewRepository, slackRepo repository.SlackRepository) *ExportUsecaseImpl {
+	return &ExportUsecaseImpl{
+		reviewRepo: reviewRepo,
+		slackRepo:  slackRepo,
+	}
+}
+
+func (u *ExportUsecaseImpl) ExportReviews(ctx context.Context, query model.ExportQuery, w io.Writer) error {
+	ctx, span := tracer.Start(ctx, "ExportUsecase.ExportReviews")
+	defer span.End()
+	workspaceURL, err := u.slackRepo.GetWorkspaceURL(ctx)
+	if err != nil {
+		// The records are still useful without links to their threads
+		slog.WarnContext(ctx, "failed to get workspace URL, exporting without permalinks", "error", err)
+	}
+	return u.Export(query, workspaceURL, w)
+}
+
+// Export writes the reviews selected by the query to w, linking to their threads in the workspace if its URL is given
+func (u *ExportUsecaseImpl) Export(query model.ExportQuery, workspaceURL string, w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	records := newRecordWriter(query.Format, buffered)
+	err := u.reviewRepo.Each(func(review *model.Review) error {
+		if !query.Matches(review) {
+			return nil
+		}
+		return records.Write(model.NewReviewRecord(review, workspaceURL))
+	})
+	if err != nil {
+		return err
+	}
+	if err := records.Close(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// recordWriter encodes review records one at a time
+type recordWriter interface {
+	Write(record *model.ReviewRecord) error
+	// Close writes whatever the format needs after the last record
+	Close() error
+}
+
+func newRecordWriter(format model.ExportFormat, w io.Writer) recordWriter {
+	switch format {
+	case model.ExportFormatJSON:
+		return &jsonRecordWriter{w: w, encoder: json.NewEncoder(w)}
+	case model.ExportFormatNDJSON:
+		return &ndjsonRecordWriter{encoder: json.NewEncoder(w)}
+	default:
+		return &csvRecordWriter{w: csv.NewWriter(w)}
+	}
+}
+
+type csvRecordWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvRecordWriter) Write(record *model.ReviewRecord) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write(record.CSVRow())
+}
+
+func (c *csvRecordWriter) Close() error {
+	// An export without any review still has a header
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRecordWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(model.ReviewRecordCSVHeader)
+}
+
+// jsonRecordWriter writes the records as the elements of a single array
+type jsonRecordWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func (j *jsonRecordWriter) Write(record *model.ReviewRecord) error {
+	separator := ","
+	if j.count == 0 {
+		separator = "["
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	return j.encoder.Encode(record)
+}
+
+func (j *jsonRecordWriter) Close() error {
+	closing := "]\n"
+	if j.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+type ndjsonRecordWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonRecordWriter) Write(record *model.ReviewRecord) error {
+	return n.encoder.Encode(record)
+}
+
+func (n *ndjsonRecordWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+type HealthUsecase interface {
+	// Live reports whether the process is alive
+	Live(ctx context.Context) *model.HTTPResponse
+	// Ready reports whether the server can handle Slack events, with the result of each dependency check
+	Ready(ctx context.Context) *model.HTTPResponse
+}
+
+// HealthUsecaseOptions configures the readiness checks of HealthUsecaseImpl
+type HealthUsecaseOptions struct {
+	// ReviewerMapError is why the reviewer configuration could not be loaded, if it could not
+	ReviewerMapError error
+	// AuthTestInterval is how often auth.test is called by MonitorSlackAuth
+	AuthTestInterval time.Duration
+	// AuthTestRetryBackoff is the first delay before retrying auth.test until it succeeds once; it doubles up to AuthTestInterval
+	AuthTestRetryBackoff time.Duration
+	// AuthTestMaxAge is how old the last successful auth.test may be
+	AuthTestMaxAge time.Duration
+}
+
+type HealthUsecaseImpl struct {
+	slackRepo    repository.SlackRepository
+	reviewerRepo repository.ReviewerRepository
+	reviewRepo   repository.ReviewRepository
+	options      HealthUsecaseOptions
+
+	mu sync.RWMutex
+	// lastAuthTestAt and lastAuthTestErr hold the result of the last auth.test
+	lastAuthTestAt  time.Time
+	lastAuthTestErr error
+}
+
+var _ HealthUsecase = (*HealthUsecaseImpl)(nil)
+
+func NewHealthUsecase(
+	slackRepo repository.SlackRepository,
+	reviewerRepo repository.ReviewerRepository,
+	reviewRepo repository.ReviewRepository,
+	options HealthUsecaseOptions,
+) *HealthUsecaseImpl {
+	return &HealthUsecaseImpl{
+		slackRepo:    slackRepo,
+		reviewerRepo: reviewerRepo,
+		reviewRepo:   reviewRepo,
+		options:      options,
+	}
+}
+
+func (u *HealthUsecaseImpl) Live(_ context.Context) *model.HTTPResponse {
+	return healthResponse(model.NewHealthReport())
+}
+
+func (u *HealthUsecaseImpl) Ready(_ context.Context) *model.HTTPResponse {
+	report := model.NewHealthReport()
+	// Reviewer configuration; once the roster has been changed through the API, the file no longer matters
+	roster, err := u.reviewerRepo.GetRoster()
+	switch {
+	case err != nil:
+		report.Fail("reviewer_config", err.Error())
+	case len(roster.Reviewers) == 0 && u.options.ReviewerMapError != nil:
+		report.Fail("reviewer_config", u.options.ReviewerMapError.Error())
+	case len(roster.Reviewers) == 0:
+		report.Fail("reviewer_config", "no reviewers configured")
+	default:
+		paused := len(roster.Reviewers) - len(roster.Available(time.Now()))
+		report.Pass("reviewer_config", fmt.Sprintf("%d reviewers (%d paused)", len(roster.Reviewers), paused))
+	}
+	// Review store
+	if err := u.reviewRepo.Ping(); err != nil {
+		report.Fail("review_store", err.Error())
+	} else {
+		report.Pass("review_store", "")
+	}
+	// Slack auth
+	u.mu.RLock()
+	lastAuthTestAt, lastAuthTestErr := u.lastAuthTestAt, u.lastAuthTestErr
+	u.mu.RUnlock()
+	switch {
+	case lastAuthTestAt.IsZero():
+		report.Fail("slack_auth", "auth.test has not completed yet")
+	case lastAuthTestErr != nil:
+		report.Fail("slack_auth", lastAuthTestErr.Error())
+	case time.Since(lastAuthTestAt) > u.options.AuthTestMaxAge:
+		report.Fail("slack_auth", "last auth.test at "+lastAuthTestAt.Format(time.RFC3339)+" is stale")
+	default:
+		report.Pass("slack_auth", "last auth.test at "+lastAuthTestAt.Format(time.RFC3339))
+	}
+	return healthResponse(report)
+}
+
+// MonitorSlackAuth calls auth.test right away and then at the configured interval until ctx is done.
+// Until the first success it retries with a short backoff instead, so a transient failure at startup does not
+// keep the server unready for a whole interval.
+func (u *HealthUsecaseImpl) MonitorSlackAuth(ctx context.Context) {
+	backoff := u.options.AuthTestRetryBackoff
+	succeeded := false
+	for {
+		testCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := u.slackRepo.TestAuth(testCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		u.mu.Lock()
+		u.lastAuthTestAt, u.lastAuthTestErr = time.Now(), err
+		u.mu.Unlock()
+		succeeded = succeeded || err == nil
+		wait := u.options.AuthTestInterval
+		if !succeeded && backoff > 0 && backoff < wait {
+			wait = backoff
+			backoff *= 2
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// healthResponse returns the report as JSON with 200, or 503 when a check failed
+func healthResponse(report *model.HealthReport) *model.HTTPResponse {
+	statusCode := http.StatusOK
+	if !report.Healthy() {
+		statusCode = http.StatusServiceUnavailable
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		slog.Error("failed to marshal health report", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return model.NewJSONResponse(statusCode, body)
+}
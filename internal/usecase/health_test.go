@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// flakyAuth fails auth.test a number of times before it succeeds
+type flakyAuth struct {
+	repository.SlackRepository
+	failures int32
+	calls    atomic.Int32
+}
+
+func (f *flakyAuth) TestAuth(_ context.Context) error {
+	if f.calls.Add(1) <= f.failures {
+		return errors.New("slack is unavailable")
+	}
+	return nil
+}
+
+func TestMonitorSlackAuthRetriesUntilFirstSuccess(t *testing.T) {
+	slackRepo := &flakyAuth{failures: 3}
+	u := NewHealthUsecase(slackRepo, nil, nil, HealthUsecaseOptions{
+		AuthTestInterval:     time.Hour,
+		AuthTestRetryBackoff: time.Millisecond,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		u.MonitorSlackAuth(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		u.mu.RLock()
+		at, err := u.lastAuthTestAt, u.lastAuthTestErr
+		u.mu.RUnlock()
+		if !at.IsZero() && err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("auth.test has not succeeded after %d calls", slackRepo.calls.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// After the first success, the next call waits for the interval
+	time.Sleep(20 * time.Millisecond)
+	if got := slackRepo.calls.Load(); got != 4 {
+		t.Errorf("auth.test calls = %d, want 4", got)
+	}
+}
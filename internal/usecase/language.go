@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// localizer returns the localizer of a message in the channel addressed to the member, if there is one.
+// The Slack locale of the member wins over the language of the channel, which wins over that of the workspace.
+func (u *SlackUsecaseImpl) localizer(ctx context.Context, channelID string, memberID model.MemberID) *i18n.Localizer {
+	if u.options.UserLocale && memberID != "" {
+		locale, err := u.slackRepo.GetUserLocale(ctx, memberID)
+		if err != nil {
+			// The language of the channel will do
+			slog.WarnContext(ctx, "failed to get user locale", "member_id", memberID, "error", err)
+		} else if language, ok := i18n.ParseLanguage(locale); ok {
+			return u.catalog.Localizer(language)
+		}
+	}
+	if language, ok := u.options.ChannelLanguages[channelID]; ok {
+		return u.catalog.Localizer(language)
+	}
+	return u.catalog.Localizer(u.options.Language)
+}
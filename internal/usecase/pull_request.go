@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// maxPullRequestPaths is how many changed paths the assignment message lists
+const maxPullRequestPaths = 5
+
+// findPullRequestURL returns the URL of the first pull request on any of the code hosts linked to in the text,
+// or else in the message starting the thread, or an empty string if there is none
+func (u *SlackUsecaseImpl) findPullRequestURL(ctx context.Context, channelID, threadTS, text string) string {
+	if refs := u.codeHosts.FindPullRequestRefs(text); len(refs) > 0 {
+		return refs[0].URL()
+	}
+	if threadTS == "" {
+		return ""
+	}
+	parentText, err := u.slackRepo.GetThreadText(ctx, channelID, threadTS)
+	if err != nil {
+		// The review can be requested without knowing what is reviewed
+		slog.WarnContext(ctx, "failed to get the message starting the thread", "error", err)
+		return ""
+	}
+	if refs := u.codeHosts.FindPullRequestRefs(parentText); len(refs) > 0 {
+		return refs[0].URL()
+	}
+	return ""
+}
+
+// getPullRequest returns the pull request the external reference of a review links to,
+// or nil if it links to none or the pull request cannot be looked up
+func (u *SlackUsecaseImpl) getPullRequest(ctx context.Context, externalRef string) *model.PullRequest {
+	ref, codeHost, ok := u.codeHosts.ParsePullRequestURL(externalRef)
+	if !ok {
+		return nil
+	}
+	pullRequest, err := codeHost.GetPullRequest(ctx, ref)
+	if errors.Is(err, repository.ErrCodeHostDisabled) {
+		return nil
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get pull request, assigning without its details", "pull_request", ref.String(), "error", err)
+		return nil
+	}
+	return pullRequest
+}
+
+// reviewExternalRef returns what the review in the thread is about, if known
+func (u *SlackUsecaseImpl) reviewExternalRef(ctx context.Context, channelID, threadTS string) string {
+	review, err := u.reviewRepo.Get(model.ReviewID(channelID, threadTS))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get review, assigning without its pull request", "error", err)
+		return ""
+	}
+	return review.ExternalRef
+}
+
+// requestCodeHostReview asks the login of the reviewer on the code host for a review of the pull request
+// the external reference of a review links to, withdrawing the request from the previous reviewer on reassignment.
+// Reviewers are still assigned in Slack when this fails.
+func (u *SlackUsecaseImpl) requestCodeHostReview(ctx context.Context, roster *model.Roster, externalRef string, reviewer model.Member, previous *model.Member) {
+	ref, codeHost, ok := u.codeHosts.ParsePullRequestURL(externalRef)
+	if !ok {
+		return
+	}
+	if previous != nil {
+		if login := reviewerLogin(roster, ref.Host, previous.MemberID); login != "" {
+			err := codeHost.RemoveRequestedReviewer(ctx, ref, login)
+			if err != nil && !errors.Is(err, repository.ErrCodeHostDisabled) {
+				slog.WarnContext(ctx, "failed to withdraw review request", "host", ref.Host, "pull_request", ref.String(), "login", login, "error", err)
+			}
+		}
+	}
+	login := reviewerLogin(roster, ref.Host, reviewer.MemberID)
+	if login == "" {
+		slog.InfoContext(ctx, "reviewer has no login on the code host, not requesting a review there", "host", ref.Host, "reviewer", reviewer.DisplayName)
+		return
+	}
+	err := codeHost.RequestReviewer(ctx, ref, login)
+	switch {
+	case errors.Is(err, repository.ErrCodeHostDisabled):
+	case err != nil:
+		// e.g. the reviewer is the author of the pull request or cannot access the repository
+		slog.WarnContext(ctx, "failed to request review", "host", ref.Host, "pull_request", ref.String(), "login", login, "error", err)
+	default:
+		slog.InfoContext(ctx, "requested review", "host", ref.Host, "pull_request", ref.String(), "login", login)
+	}
+}
+
+// reviewerLogin returns the login on the code host of the reviewer with the member ID, or an empty string if there is none
+func reviewerLogin(roster *model.Roster, host model.CodeHost, memberID model.MemberID) string {
+	reviewer, ok := roster.Find(memberID)
+	if !ok {
+		return ""
+	}
+	return reviewer.Login(host)
+}
+
+// codeOwners returns the available reviewers whose logins own any of the paths the pull request changes,
+// leaving out its author and the excluded member. Teams, groups and email addresses in the CODEOWNERS file are not mapped.
+func (u *SlackUsecaseImpl) codeOwners(ctx context.Context, roster *model.Roster, pullRequest *model.PullRequest, excluded model.MemberID) []model.Member {
+	if pullRequest == nil {
+		return nil
+	}
+	codeHost, ok := u.codeHosts.Get(pullRequest.Host)
+	if !ok {
+		return nil
+	}
+	codeOwners, err := codeHost.GetCodeOwners(ctx, pullRequest.PullRequestRef, pullRequest.Base)
+	if errors.Is(err, repository.ErrCodeHostDisabled) {
+		return nil
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get code owners", "pull_request", pullRequest.PullRequestRef.String(), "error", err)
+		return nil
+	}
+	if codeOwners == nil {
+		return nil
+	}
+	available := roster.Available(time.Now())
+	var owners []model.Member
+	for _, owner := range codeOwners.OwnersOf(pullRequest.Paths) {
+		login, ok := model.CodeOwnerLogin(owner)
+		if !ok || strings.EqualFold(login, pullRequest.Author) {
+			continue
+		}
+		reviewer, ok := roster.FindByLogin(pullRequest.Host, login)
+		if !ok || reviewer.MemberID == excluded || available[reviewer.DisplayName] != reviewer.MemberID {
+			continue
+		}
+		owners = append(owners, reviewer.Member)
+	}
+	return owners
+}
+
+// codeOwnerFilter restricts the members to choose from to the code owners, keeping only those among them
+// if members were already given
+func codeOwnerFilter(filterMemberIDs []model.MemberID, codeOwners []model.Member) []model.MemberID {
+	ownerIDs := model.MemberIDs(codeOwners)
+	if len(filterMemberIDs) == 0 {
+		return ownerIDs
+	}
+	return slices.DeleteFunc(ownerIDs, func(memberID model.MemberID) bool {
+		return !slices.Contains(filterMemberIDs, memberID)
+	})
+}
+
+// pullRequestFields describes the pull request in the fields of the assignment message
+func pullRequestFields(l *i18n.Localizer, pullRequest *model.PullRequest) []model.AttachmentField {
+	paths := pullRequest.Paths
+	if len(paths) > maxPullRequestPaths {
+		paths = paths[:maxPullRequestPaths]
+	}
+	changed := "`" + strings.Join(paths, "`\n`") + "`"
+	if rest := len(pullRequest.Paths) - len(paths); rest > 0 {
+		changed += "\n" + l.Plural("pull_request.more_files", rest)
+	}
+	fields := []model.AttachmentField{
+		{
+			Title: l.T("pull_request." + string(pullRequest.Host)),
+			Value: fmt.Sprintf("<%s|%s> %s", pullRequest.URL(), pullRequest.PullRequestRef, pullRequest.Title),
+		},
+		{
+			Title: l.T("pull_request.author"),
+			Value: pullRequest.Author,
+			Short: true,
+		},
+		{
+			Title: l.T("pull_request.size"),
+			Value: l.Plural("pull_request.size_value", pullRequest.ChangedFiles, pullRequest.Additions, pullRequest.Deletions),
+			Short: true,
+		},
+	}
+	if len(paths) > 0 {
+		fields = append(fields, model.AttachmentField{Title: l.T("pull_request.changed_files"), Value: changed})
+	}
+	return fields
+}
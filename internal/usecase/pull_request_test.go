@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+// fakeCodeHost is an in-memory GitHub knowing the given pull requests, or a disabled one without any
+type fakeCodeHost struct {
+	repository.CodeHost
+	pullRequests map[model.PullRequestRef]*model.PullRequest
+}
+
+func (f *fakeCodeHost) Host() model.CodeHost {
+	return model.CodeHostGitHub
+}
+
+func (f *fakeCodeHost) FindPullRequestRefs(text string) []model.PullRequestRef {
+	return model.FindGitHubPullRequestRefs(text)
+}
+
+func (f *fakeCodeHost) GetPullRequest(_ context.Context, ref model.PullRequestRef) (*model.PullRequest, error) {
+	if f.pullRequests == nil {
+		return nil, repository.ErrCodeHostDisabled
+	}
+	pullRequest, ok := f.pullRequests[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return pullRequest, nil
+}
+
+// fakeThreads is a Slack workspace whose threads start with the given messages
+type fakeThreads struct {
+	repository.SlackRepository
+	texts map[string]string
+}
+
+func (f *fakeThreads) GetThreadText(_ context.Context, _, threadTS string) (string, error) {
+	return f.texts[threadTS], nil
+}
+
+const testPullRequestURL = "https://github.com/octo/bot/pull/7"
+
+func TestFindPullRequestURL(t *testing.T) {
+	u := &SlackUsecaseImpl{
+		slackRepo: &fakeThreads{texts: map[string]string{"1.0": "<" + testPullRequestURL + "|octo/bot#7> please"}},
+		codeHosts: repository.CodeHosts{&fakeCodeHost{}},
+	}
+	tests := []struct {
+		name     string
+		threadTS string
+		text     string
+		want     string
+	}{
+		{name: "in the mention", text: "<@UBOT> https://github.com/octo/bot/pull/8", threadTS: "1.0", want: "https://github.com/octo/bot/pull/8"},
+		{name: "in the message starting the thread", text: "<@UBOT>", threadTS: "1.0", want: testPullRequestURL},
+		{name: "nowhere", text: "<@UBOT>", threadTS: "2.0", want: ""},
+		{name: "outside of a thread", text: "<@UBOT>", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.findPullRequestURL(context.Background(), "C1", tt.threadTS, tt.text); got != tt.want {
+				t.Errorf("findPullRequestURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	ref := model.PullRequestRef{Host: model.CodeHostGitHub, Owner: "octo", Repo: "bot", Number: 7}
+	pullRequest := &model.PullRequest{PullRequestRef: ref, Title: "Add reviews", Paths: []string{"main.go"}}
+	enabled := &SlackUsecaseImpl{codeHosts: repository.CodeHosts{&fakeCodeHost{pullRequests: map[model.PullRequestRef]*model.PullRequest{ref: pullRequest}}}}
+	disabled := &SlackUsecaseImpl{codeHosts: repository.CodeHosts{&fakeCodeHost{}}}
+	ctx := context.Background()
+
+	if got := enabled.getPullRequest(ctx, testPullRequestURL); got != pullRequest {
+		t.Errorf("getPullRequest() = %+v, want the pull request", got)
+	}
+	// Reviews are still requested without the details of their pull requests
+	if got := disabled.getPullRequest(ctx, testPullRequestURL); got != nil {
+		t.Errorf("getPullRequest() with the code host disabled = %+v, want nil", got)
+	}
+	if got := enabled.getPullRequest(ctx, "https://example.com/octo/bot/pull/7"); got != nil {
+		t.Errorf("getPullRequest() of another link = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// reviewUpdateAttempts is how often an update is retried after losing an optimistic locking conflict
+const reviewUpdateAttempts = 5
+
+// updateReview loads the review in the thread, applies fn and saves it, retrying on conflicts.
+// newReview creates the review when it does not exist yet. fn returns false to leave the review unchanged,
+// in which case the review is returned as is and the returned bool is false.
+func (u *SlackUsecaseImpl) updateReview(
+	channelID, threadTS string,
+	newReview func() *model.Review,
+	fn func(review *model.Review) bool,
+) (*model.Review, bool, error) {
+	id := model.ReviewID(channelID, threadTS)
+	var err error
+	for attempt := 0; attempt < reviewUpdateAttempts; attempt++ {
+		var review *model.Review
+		review, err = u.reviewRepo.Get(id)
+		if errors.Is(err, repository.ErrReviewNotFound) {
+			review, err = newReview(), nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !fn(review) {
+			return review, false, nil
+		}
+		err = u.reviewRepo.Save(review)
+		if errors.Is(err, repository.ErrReviewConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return review, true, nil
+	}
+	return nil, false, err
+}
+
+// reopenReview makes the review in the thread selectable again, recording the requester and what is reviewed if known
+func (u *SlackUsecaseImpl) reopenReview(ctx context.Context, channelID, threadTS string, requesterID model.MemberID, externalRef string) {
+	now := time.Now()
+	_, _, err := u.updateReview(
+		channelID,
+		threadTS,
+		func() *model.Review {
+			return model.NewReview(channelID, threadTS, requesterID, now)
+		},
+		func(review *model.Review) bool {
+			if requesterID != "" {
+				review.RequesterID = requesterID
+			}
+			if externalRef != "" {
+				review.ExternalRef = externalRef
+			}
+			review.Reopen(now)
+			return true
+		},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to reopen review", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+}
+
+// openReview creates the review requested in the thread, or makes it selectable again if nobody is being assigned to it,
+// recording the requester and what is reviewed if known.
+// It returns the review and false when the thread has a reviewer or is done, which a new request leaves as it is.
+func (u *SlackUsecaseImpl) openReview(channelID, threadTS string, requesterID model.MemberID, externalRef string) (*model.Review, bool, error) {
+	now := time.Now()
+	return u.updateReview(
+		channelID,
+		threadTS,
+		func() *model.Review {
+			return model.NewReview(channelID, threadTS, requesterID, now)
+		},
+		func(review *model.Review) bool {
+			if !review.CanReopen(now) {
+				return false
+			}
+			if requesterID != "" {
+				review.RequesterID = requesterID
+			}
+			if externalRef != "" {
+				review.ExternalRef = externalRef
+			}
+			review.Reopen(now)
+			return true
+		},
+	)
+}
+
+// claimReview lets exactly one interaction on the review proceed.
+// It returns the review and false when another interaction has already claimed it.
+func (u *SlackUsecaseImpl) claimReview(ctx context.Context, event *model.InteractiveMessageEvent, mode model.AssignmentMode) (*model.Review, bool, error) {
+	now := time.Now()
+	return u.updateReview(
+		event.ChannelID,
+		event.ThreadTS,
+		func() *model.Review {
+			// The selection message may predate the store, so trust what the message shows
+			review := model.NewReview(event.ChannelID, event.ThreadTS, "", now)
+			if mode == model.AssignmentModeReassign {
+				review.Assign(model.Member{DisplayName: event.Value, MemberID: u.allReviewers(ctx)[event.Value]}, now)
+			}
+			return review
+		},
+		func(review *model.Review) bool {
+			if !review.CanClaim(mode, event.Value, now) {
+				return false
+			}
+			review.Claim(event.MemberID, mode, now)
+			return true
+		},
+	)
+}
+
+// assignReview records the reviewer chosen for the claimed review in the thread
+func (u *SlackUsecaseImpl) assignReview(ctx context.Context, channelID, threadTS string, reviewer model.Member) {
+	now := time.Now()
+	_, _, err := u.updateReview(
+		channelID,
+		threadTS,
+		func() *model.Review {
+			return model.NewReview(channelID, threadTS, "", now)
+		},
+		func(review *model.Review) bool {
+			review.Assign(reviewer, now)
+			return true
+		},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record assignment", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+}
+
+// alreadyClaimedResponse tells the user who lost the race for the review who won it
+func (u *SlackUsecaseImpl) alreadyClaimedResponse(ctx context.Context, l *i18n.Localizer, review *model.Review) *model.HTTPResponse {
+	return ephemeralResponse(ctx, reviewTakenText(l, review))
+}
+
+// reviewTakenText tells why the review can be neither claimed nor requested again
+func reviewTakenText(l *i18n.Localizer, review *model.Review) string {
+	switch {
+	case review.Status.IsDone():
+		return l.T("assignment.already_finished")
+	case review.Status.IsInReview():
+		return l.T("assignment.already_assigned", review.Reviewer.DisplayName)
+	default:
+		return l.T("assignment.already_claimed", "<@"+string(review.ClaimedBy)+">")
+	}
+}
+
+// completeReview marks the review on the message as done if memberID is its assigned reviewer.
+// It returns the completed review and false when there was nothing to complete.
+func (u *SlackUsecaseImpl) completeReview(ctx context.Context, channelID, messageTS string, memberID model.MemberID) (*model.Review, bool) {
+	now := time.Now()
+	review, completed, err := u.updateReview(
+		channelID,
+		messageTS,
+		func() *model.Review {
+			// Reactions on messages that are not review requests are none of our business
+			return model.NewReview(channelID, messageTS, "", now)
+		},
+		func(review *model.Review) bool {
+			if !review.Status.IsInReview() || review.Reviewer.MemberID != memberID {
+				return false
+			}
+			review.Complete(now)
+			return true
+		},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to complete review", "channel", channelID, "thread_ts", messageTS, "error", err)
+		return nil, false
+	}
+	return review, completed
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReviewRequestUsecase interface {
+	// CreateReviewRequest requests a review and assigns a reviewer right away, as if the mode had been picked
+	// on the selection message, and returns the review as JSON
+	CreateReviewRequest(ctx context.Context, request *model.ReviewRequest) *model.HTTPResponse
+	// GetReviewRequest returns the review request found by the query with its reviewers and approvals as JSON,
+	// once it has changed or the query has waited long enough
+	GetReviewRequest(ctx context.Context, query model.ReviewRequestQuery) *model.HTTPResponse
+}
+
+// reviewPollInterval is how often a waiting request checks whether the review has changed
+const reviewPollInterval = 500 * time.Millisecond
+
+var _ ReviewRequestUsecase = (*SlackUsecaseImpl)(nil)
+
+func (u *SlackUsecaseImpl) CreateReviewRequest(ctx context.Context, request *model.ReviewRequest) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.CreateReviewRequest", trace.WithAttributes(
+		attribute.String("slack.channel_id", request.ChannelID),
+		attribute.String("review.mode", string(request.Mode)),
+	))
+	defer span.End()
+	if err := request.Validate(); err != nil {
+		return model.NewTextResponse(http.StatusBadRequest, []byte(err.Error()))
+	}
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	choice := reviewerChoice{Mode: request.Mode, RequesterID: request.RequesterID}
+	for _, nameOrMemberID := range request.Reviewers {
+		reviewer, ok := roster.Lookup(nameOrMemberID)
+		if !ok {
+			return model.NewTextResponse(http.StatusBadRequest, []byte("unknown reviewer: "+nameOrMemberID))
+		}
+		if request.Mode == model.AssignmentModeSelect {
+			choice.Name = reviewer.DisplayName
+		} else {
+			choice.FilterMemberIDs = append(choice.FilterMemberIDs, reviewer.MemberID)
+		}
+	}
+
+	externalRef := request.ExternalRef
+	if externalRef == "" {
+		externalRef = u.findPullRequestURL(ctx, request.ChannelID, request.ThreadTS, request.Text)
+	}
+	pullRequest := u.getPullRequest(ctx, externalRef)
+	if request.Mode == model.AssignmentModeSuggested {
+		choice.FilterMemberIDs = codeOwnerFilter(choice.FilterMemberIDs, u.codeOwners(ctx, roster, pullRequest, request.RequesterID))
+	}
+
+	// Choose the reviewer before posting anything, so that a request nobody can take leaves no message behind
+	now := time.Now()
+	assignment := model.NewAuditEvent(model.AuditEventAssigned, request.ChannelID, request.ThreadTS, request.RequesterID, now)
+	reviewer, ok, err := u.chooseReviewer(ctx, roster, choice, assignment)
+	if err != nil {
+		return model.NewStatusResponse(http.StatusBadGateway)
+	}
+	if !ok {
+		return model.NewTextResponse(http.StatusUnprocessableEntity, []byte("no reviewer available"))
+	}
+
+	// Post the review request unless it is already in a thread
+	threadTS := request.ThreadTS
+	if threadTS == "" {
+		threadTS, err = u.slackRepo.PostMessage(ctx, model.NewMessage(request.ChannelID, request.Text, nil, false, ""))
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to post review request", "error", err)
+			return model.NewStatusResponse(http.StatusBadGateway)
+		}
+		assignment.ThreadTS, assignment.ReviewID = threadTS, model.ReviewID(request.ChannelID, threadTS)
+	}
+	// A review request posted here that cannot be assigned gets the selection message, so that it is not left unanswered
+	fallback := func() {
+		if request.ThreadTS == "" {
+			u.sendReviewerSelectionMessage(ctx, request.ChannelID, threadTS, request.RequesterID, externalRef)
+		}
+	}
+	review, claimed, err := u.updateReview(
+		request.ChannelID,
+		threadTS,
+		func() *model.Review {
+			return model.NewReview(request.ChannelID, threadTS, request.RequesterID, now)
+		},
+		func(review *model.Review) bool {
+			if !review.CanClaim(request.Mode, "", now) {
+				return false
+			}
+			if request.RequesterID != "" {
+				review.RequesterID = request.RequesterID
+			}
+			if externalRef != "" {
+				review.ExternalRef = externalRef
+			}
+			review.Claim(request.RequesterID, request.Mode, now)
+			return true
+		},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to claim review", "error", err)
+		fallback()
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	if !claimed {
+		slog.InfoContext(ctx, "review already claimed", "review_id", review.ID, "status", review.Status)
+		return u.reviewResponse(ctx, http.StatusConflict, review)
+	}
+	u.recordAudit(ctx, model.NewAuditEvent(model.AuditEventRequested, request.ChannelID, threadTS, request.RequesterID, now))
+
+	// Assign the reviewer the same way as an interaction on the selection message does
+	message := newAssignmentMessage(u.localizer(ctx, request.ChannelID, reviewer.MemberID), request.ChannelID, threadTS, reviewer, request.Mode, pullRequest)
+	if _, err := u.slackRepo.PostMessage(ctx, message); err != nil {
+		slog.ErrorContext(ctx, "failed to post assignment", "error", err)
+		u.reopenReview(ctx, request.ChannelID, threadTS, "", "")
+		fallback()
+		return model.NewStatusResponse(http.StatusBadGateway)
+	}
+	u.assignReview(ctx, request.ChannelID, threadTS, reviewer)
+	u.requestCodeHostReview(ctx, roster, review.ExternalRef, reviewer, nil)
+	assignment.Reviewer = &reviewer
+	u.recordAudit(ctx, assignment)
+	slog.InfoContext(ctx, "review requested through the API", "review_id", review.ID, "reviewer", reviewer.DisplayName)
+
+	assigned, err := u.reviewRepo.Get(review.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get review", "review_id", review.ID, "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return u.reviewResponse(ctx, http.StatusCreated, assigned)
+}
+
+func (u *SlackUsecaseImpl) GetReviewRequest(ctx context.Context, query model.ReviewRequestQuery) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.GetReviewRequest", trace.WithAttributes(
+		attribute.String("review.id", query.ID),
+		attribute.Int64("review.wait_ms", query.Wait.Milliseconds()),
+	))
+	defer span.End()
+	if err := query.Validate(); err != nil {
+		return model.NewTextResponse(http.StatusBadRequest, []byte(err.Error()))
+	}
+	review, err := u.findReview(query)
+	if errors.Is(err, repository.ErrReviewNotFound) {
+		return model.NewTextResponse(http.StatusNotFound, []byte(err.Error()))
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find review", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+
+	// Wait for the review to change by polling, as the store cannot notify of changes
+	version := query.Version
+	if version == 0 {
+		version = review.Version
+	}
+	if query.Wait > 0 && review.Version == version {
+		timer := time.NewTimer(query.Wait)
+		defer timer.Stop()
+		ticker := time.NewTicker(reviewPollInterval)
+		defer ticker.Stop()
+	wait:
+		for review.Version == version {
+			select {
+			case <-ctx.Done():
+				// The client is gone, so nobody reads the answer
+				return model.NewStatusResponse(http.StatusServiceUnavailable)
+			case <-timer.C:
+				break wait
+			case <-ticker.C:
+				if review, err = u.reviewRepo.Get(review.ID); err != nil {
+					slog.ErrorContext(ctx, "failed to get review", "error", err)
+					return model.NewStatusResponse(http.StatusInternalServerError)
+				}
+			}
+		}
+	}
+
+	events, err := u.auditRepo.List(model.ReviewAuditQuery(review.ChannelID, review.ThreadTS))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list audit events", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	workspaceURL, err := u.slackRepo.GetWorkspaceURL(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get workspace URL, answering without a permalink", "error", err)
+	}
+	return jsonResponse(ctx, http.StatusOK, model.NewReviewDetail(review, events, workspaceURL))
+}
+
+// findReview returns the review named by the query. Reviews are not indexed by their external reference,
+// so the newest review with it is found by going through all of them.
+func (u *SlackUsecaseImpl) findReview(query model.ReviewRequestQuery) (*model.Review, error) {
+	switch {
+	case query.ThreadLink != "":
+		channelID, threadTS, ok := model.ParseThreadLink(query.ThreadLink)
+		if !ok {
+			return nil, repository.ErrReviewNotFound
+		}
+		return u.reviewRepo.Get(model.ReviewID(channelID, threadTS))
+	case query.ExternalRef != "":
+		var found *model.Review
+		err := u.reviewRepo.Each(func(review *model.Review) error {
+			if review.ExternalRef == query.ExternalRef && (found == nil || review.CreatedAt.After(found.CreatedAt)) {
+				found = review
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			return nil, repository.ErrReviewNotFound
+		}
+		return found, nil
+	default:
+		return u.reviewRepo.Get(query.ID)
+	}
+}
+
+// reviewResponse answers with the review as exported, linking to its thread if the workspace URL is known
+func (u *SlackUsecaseImpl) reviewResponse(ctx context.Context, statusCode int, review *model.Review) *model.HTTPResponse {
+	workspaceURL, err := u.slackRepo.GetWorkspaceURL(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get workspace URL, answering without a permalink", "error", err)
+	}
+	return jsonResponse(ctx, statusCode, model.NewReviewRecord(review, workspaceURL))
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// fakeRoster always returns the same roster
+type fakeRoster struct {
+	repository.ReviewerRepository
+	roster *model.Roster
+}
+
+func (f *fakeRoster) GetRoster() (*model.Roster, error) {
+	return f.roster, nil
+}
+
+// brokenReviews is a review store that cannot be read
+type brokenReviews struct {
+	repository.ReviewRepository
+}
+
+func (brokenReviews) Get(string) (*model.Review, error) {
+	return nil, errors.New("store is unavailable")
+}
+
+// discardAudit accepts every audit event
+type discardAudit struct {
+	repository.AuditRepository
+}
+
+func (discardAudit) Append(*model.AuditEvent) error {
+	return nil
+}
+
+func TestCreateReviewRequestLeavesNoUnansweredMessage(t *testing.T) {
+	roster := model.NewRoster(model.ReviewerMap{"alice": "U1", "bob": "U2"}, nil)
+	tests := []struct {
+		name       string
+		request    model.ReviewRequest
+		wantStatus int
+		// wantPosted are whether each posted message has buttons, i.e. is the selection message
+		wantPosted []bool
+	}{
+		{
+			name:       "no reviewer",
+			request:    model.ReviewRequest{ChannelID: "C1", Text: "please review", Reviewers: []string{"alice"}, RequesterID: "U1"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantPosted: nil,
+		},
+		{
+			name:       "store failure",
+			request:    model.ReviewRequest{ChannelID: "C1", Text: "please review", RequesterID: "U1"},
+			wantStatus: http.StatusInternalServerError,
+			wantPosted: []bool{false, true},
+		},
+		{
+			name:       "store failure in an existing thread",
+			request:    model.ReviewRequest{ChannelID: "C1", ThreadTS: "1.0", RequesterID: "U1"},
+			wantStatus: http.StatusInternalServerError,
+			wantPosted: []bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slackRepo := &fakeMessages{}
+			u := &SlackUsecaseImpl{
+				slackRepo:    slackRepo,
+				reviewerRepo: &fakeRoster{roster: roster},
+				reviewRepo:   brokenReviews{},
+				auditRepo:    discardAudit{},
+				codeHosts:    repository.CodeHosts{&fakeCodeHost{}},
+				catalog:      newTestCatalog(t),
+				options:      SlackUsecaseOptions{Language: i18n.English},
+			}
+			// The message starting an existing thread is read for a pull request link
+			slackRepo.SlackRepository = &fakeThreads{}
+
+			response := u.CreateReviewRequest(context.Background(), &tt.request)
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("CreateReviewRequest() status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if len(slackRepo.posted) != len(tt.wantPosted) {
+				t.Fatalf("CreateReviewRequest() posted %d messages, want %d", len(slackRepo.posted), len(tt.wantPosted))
+			}
+			for i, message := range slackRepo.posted {
+				if got := len(message.Attachments) > 0 && len(message.Attachments[0].Actions) > 0; got != tt.wantPosted[i] {
+					t.Errorf("message %d is the selection message = %v, want %v", i, got, tt.wantPosted[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+)
+
+var (
+	errReviewerNotFound = errors.New("reviewer not found")
+	errReviewerExists   = errors.New("reviewer already exists")
+)
+
+// ReviewerUsecase manages the reviewer roster. The actor is the member making the change,
+// or empty when it is made with an API key.
+type ReviewerUsecase interface {
+	// ListReviewers returns the roster as JSON
+	ListReviewers(ctx context.Context) *model.HTTPResponse
+	// CreateReviewer adds a reviewer to the roster with its logins on the code hosts, leaving out empty ones
+	CreateReviewer(ctx context.Context, actorID model.MemberID, member model.Member, logins map[model.CodeHost]string) *model.HTTPResponse
+	// UpdateReviewer changes the display name or the logins on the code hosts of a reviewer, leaving nil ones as they are.
+	// An empty login removes it.
+	UpdateReviewer(ctx context.Context, actorID, memberID model.MemberID, displayName *string, logins map[model.CodeHost]*string) *model.HTTPResponse
+	// DeleteReviewer removes a reviewer from the roster
+	DeleteReviewer(ctx context.Context, actorID, memberID model.MemberID) *model.HTTPResponse
+	// PauseReviewer keeps a reviewer from being assigned until the time, or until resumed if it is zero
+	PauseReviewer(ctx context.Context, actorID, memberID model.MemberID, until time.Time) *model.HTTPResponse
+	// ResumeReviewer makes a paused reviewer assignable again
+	ResumeReviewer(ctx context.Context, actorID, memberID model.MemberID) *model.HTTPResponse
+}
+
+type ReviewerUsecaseImpl struct {
+	reviewerRepo repository.ReviewerRepository
+	auditRepo    repository.AuditRepository
+}
+
+var _ ReviewerUsecase = (*ReviewerUsecaseImpl)(nil)
+
+func NewReviewerUsecase(reviewerRepo repository.ReviewerRepository, auditRepo repository.AuditRepository) *ReviewerUsecaseImpl {
+	return &ReviewerUsecaseImpl{
+		reviewerRepo: reviewerRepo,
+		auditRepo:    auditRepo,
+	}
+}
+
+// reviewerResource is a reviewer as returned by the API
+type reviewerResource struct {
+	*model.Reviewer
+	// Available is whether the reviewer can be assigned now, which changes when a pause ends
+	Available bool `json:"available"`
+}
+
+func newReviewerResource(reviewer *model.Reviewer, now time.Time) reviewerResource {
+	return reviewerResource{Reviewer: reviewer, Available: !reviewer.IsPaused(now)}
+}
+
+func (u *ReviewerUsecaseImpl) ListReviewers(ctx context.Context) *model.HTTPResponse {
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	now := time.Now()
+	reviewers := make([]reviewerResource, len(roster.Reviewers))
+	for i, reviewer := range roster.Reviewers {
+		reviewers[i] = newReviewerResource(reviewer, now)
+	}
+	return jsonResponse(ctx, http.StatusOK, struct {
+		Reviewers []reviewerResource `json:"reviewers"`
+	}{Reviewers: reviewers})
+}
+
+func (u *ReviewerUsecaseImpl) CreateReviewer(ctx context.Context, actorID model.MemberID, member model.Member, logins map[model.CodeHost]string) *model.HTTPResponse {
+	return u.changeRoster(ctx, actorID, http.StatusCreated, func(roster *model.Roster, now time.Time) (*model.Reviewer, string, error) {
+		if _, ok := roster.Find(member.MemberID); ok {
+			return nil, "", fmt.Errorf("%w: %s", errReviewerExists, member.MemberID)
+		}
+		reviewer := &model.Reviewer{Member: member, UpdatedAt: now}
+		for host, login := range logins {
+			roster.SetLogin(reviewer, host, login, now)
+		}
+		roster.Add(reviewer)
+		return reviewer, "added reviewer", nil
+	})
+}
+
+func (u *ReviewerUsecaseImpl) UpdateReviewer(ctx context.Context, actorID, memberID model.MemberID, displayName *string, logins map[model.CodeHost]*string) *model.HTTPResponse {
+	return u.changeRoster(ctx, actorID, http.StatusOK, func(roster *model.Roster, now time.Time) (*model.Reviewer, string, error) {
+		reviewer, ok := roster.Find(memberID)
+		if !ok {
+			return nil, "", errReviewerNotFound
+		}
+		var changes []string
+		if displayName != nil && *displayName != reviewer.DisplayName {
+			changes = append(changes, "renamed reviewer from "+reviewer.DisplayName)
+			roster.Rename(reviewer, *displayName, now)
+		}
+		for _, host := range model.AllCodeHosts {
+			login := logins[host]
+			if login == nil || *login == reviewer.Login(host) {
+				continue
+			}
+			if *login == "" {
+				changes = append(changes, "removed "+host.Name()+" login "+reviewer.Login(host))
+			} else {
+				changes = append(changes, "set "+host.Name()+" login to "+*login)
+			}
+			roster.SetLogin(reviewer, host, *login, now)
+		}
+		if len(changes) == 0 {
+			return reviewer, "updated reviewer without changes", nil
+		}
+		return reviewer, strings.Join(changes, ", "), nil
+	})
+}
+
+func (u *ReviewerUsecaseImpl) DeleteReviewer(ctx context.Context, actorID, memberID model.MemberID) *model.HTTPResponse {
+	return u.changeRoster(ctx, actorID, http.StatusOK, func(roster *model.Roster, _ time.Time) (*model.Reviewer, string, error) {
+		reviewer, ok := roster.Find(memberID)
+		if !ok {
+			return nil, "", errReviewerNotFound
+		}
+		roster.Remove(memberID)
+		return reviewer, "removed reviewer", nil
+	})
+}
+
+func (u *ReviewerUsecaseImpl) PauseReviewer(ctx context.Context, actorID, memberID model.MemberID, until time.Time) *model.HTTPResponse {
+	return u.changeRoster(ctx, actorID, http.StatusOK, func(roster *model.Roster, now time.Time) (*model.Reviewer, string, error) {
+		reviewer, ok := roster.Find(memberID)
+		if !ok {
+			return nil, "", errReviewerNotFound
+		}
+		if !until.IsZero() && !until.After(now) {
+			return nil, "", fmt.Errorf("%w: pause must end in the future", model.ErrInvalidReviewer)
+		}
+		reviewer.Pause(until, now)
+		if until.IsZero() {
+			return reviewer, "paused reviewer", nil
+		}
+		return reviewer, "paused reviewer until " + until.UTC().Format(time.RFC3339), nil
+	})
+}
+
+func (u *ReviewerUsecaseImpl) ResumeReviewer(ctx context.Context, actorID, memberID model.MemberID) *model.HTTPResponse {
+	return u.changeRoster(ctx, actorID, http.StatusOK, func(roster *model.Roster, now time.Time) (*model.Reviewer, string, error) {
+		reviewer, ok := roster.Find(memberID)
+		if !ok {
+			return nil, "", errReviewerNotFound
+		}
+		reviewer.Resume(now)
+		return reviewer, "resumed reviewer", nil
+	})
+}
+
+// changeRoster applies the change to the roster if the roster stays valid, records it in the audit log
+// and answers with the changed reviewer
+func (u *ReviewerUsecaseImpl) changeRoster(
+	ctx context.Context,
+	actorID model.MemberID,
+	statusCode int,
+	change func(roster *model.Roster, now time.Time) (reviewer *model.Reviewer, detail string, err error),
+) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "ReviewerUsecase.changeRoster")
+	defer span.End()
+	now := time.Now()
+	var reviewer model.Reviewer
+	var detail string
+	err := u.reviewerRepo.UpdateRoster(func(roster *model.Roster) error {
+		changed, d, err := change(roster, now)
+		if err != nil {
+			return err
+		}
+		if err := roster.Validate(); err != nil {
+			return err
+		}
+		reviewer, detail = *changed, d
+		return nil
+	})
+	switch {
+	case errors.Is(err, errReviewerNotFound):
+		return model.NewTextResponse(http.StatusNotFound, []byte(err.Error()))
+	case errors.Is(err, errReviewerExists):
+		return model.NewTextResponse(http.StatusConflict, []byte(err.Error()))
+	case errors.Is(err, model.ErrInvalidReviewer):
+		return model.NewTextResponse(http.StatusBadRequest, []byte(err.Error()))
+	case err != nil:
+		slog.ErrorContext(ctx, "failed to update reviewer roster", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	slog.InfoContext(ctx, "changed reviewer roster", "member_id", reviewer.MemberID, "detail", detail, "actor_id", actorID)
+	event := model.NewAuditEvent(model.AuditEventAdminChanged, "", "", actorID, now)
+	event.Reviewer = &reviewer.Member
+	event.Detail = detail
+	if actorID == "" {
+		event.Detail += " with an API key"
+	}
+	appendAuditEvent(ctx, u.auditRepo, event)
+	return jsonResponse(ctx, statusCode, newReviewerResource(&reviewer, now))
+}
+
+// allReviewers returns every reviewer of the roster, paused or not, or none if it cannot be read.
+// The roster is read on every use so that changes through the reviewer API apply right away.
+func (u *SlackUsecaseImpl) allReviewers(ctx context.Context) model.ReviewerMap {
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+		return model.ReviewerMap{}
+	}
+	return roster.ReviewerMap()
+}
+
+// jsonResponse answers with the value encoded as JSON
+func jsonResponse(ctx context.Context, statusCode int, v any) *model.HTTPResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal response", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return model.NewJSONResponse(statusCode, body)
+}
@@ -1,67 +1,192 @@
 package usecase
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
 	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"github.com/himura467/slack-review-request-bot/internal/logging"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/himura467/slack-review-request-bot/internal/usecase")
+
 type SlackUsecase interface {
-	HandleEvent(r *model.HTTPRequest) *model.HTTPResponse
-	HandleInteraction(r *model.HTTPRequest) *model.HTTPResponse
+	HandleEvent(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse
+	HandleInteraction(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse
+	HandleCommand(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse
+}
+
+// SlackUsecaseOptions configures optional behavior of SlackUsecaseImpl
+type SlackUsecaseOptions struct {
+	// FastAck acknowledges app mentions immediately and handles them in the background
+	FastAck bool
+	// Language is the language of the messages in the workspace, Japanese unless set
+	Language i18n.Language
+	// ChannelLanguages are the languages of the channels whose messages are written in another language
+	ChannelLanguages map[string]i18n.Language
+	// UserLocale writes the messages addressed to a member in the language of their Slack locale if there is a bundle for it
+	UserLocale bool
 }
 
 type SlackUsecaseImpl struct {
-	slackRepo   repository.SlackRepository
-	reviewerMap model.ReviewerMap
+	slackRepo       repository.SlackRepository
+	reviewerRepo    repository.ReviewerRepository
+	jobQueue        repository.JobQueue
+	idempotencyRepo repository.IdempotencyRepository
+	reviewRepo      repository.ReviewRepository
+	auditRepo       repository.AuditRepository
+	codeHosts       repository.CodeHosts
+	catalog         *i18n.Catalog
+	options         SlackUsecaseOptions
 }
 
 var _ SlackUsecase = (*SlackUsecaseImpl)(nil)
 var _ model.EventHandler = (*SlackUsecaseImpl)(nil)
 
-func NewSlackUsecase(slackRepo repository.SlackRepository, reviewerMap model.ReviewerMap) *SlackUsecaseImpl {
-	return &SlackUsecaseImpl{
-		slackRepo:   slackRepo,
-		reviewerMap: reviewerMap,
+func NewSlackUsecase(
+	slackRepo repository.SlackRepository,
+	reviewerRepo repository.ReviewerRepository,
+	jobQueue repository.JobQueue,
+	idempotencyRepo repository.IdempotencyRepository,
+	reviewRepo repository.ReviewRepository,
+	auditRepo repository.AuditRepository,
+	codeHosts repository.CodeHosts,
+	catalog *i18n.Catalog,
+	options SlackUsecaseOptions,
+) *SlackUsecaseImpl {
+	u := &SlackUsecaseImpl{
+		slackRepo:       slackRepo,
+		reviewerRepo:    reviewerRepo,
+		jobQueue:        jobQueue,
+		idempotencyRepo: idempotencyRepo,
+		reviewRepo:      reviewRepo,
+		auditRepo:       auditRepo,
+		codeHosts:       codeHosts,
+		catalog:         catalog,
+		options:         options,
 	}
+	jobQueue.Register(jobTypeInteractiveAction, u.handleInteractiveActionJob)
+	jobQueue.Register(jobTypeAppMention, u.handleAppMentionJob)
+	return u
 }
 
 // HandleEvent processes incoming Slack events
-func (u *SlackUsecaseImpl) HandleEvent(r *model.HTTPRequest) *model.HTTPResponse {
+func (u *SlackUsecaseImpl) HandleEvent(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleEvent")
+	defer span.End()
 	// Verify the request
-	if err := u.slackRepo.VerifyRequest(r); err != nil {
-		slog.Error("failed to verify request", "error", err)
+	if err := u.slackRepo.VerifyRequest(ctx, r); err != nil {
+		slog.ErrorContext(ctx, "failed to verify request", "error", err)
 		return model.NewStatusResponse(http.StatusBadRequest)
 	}
 	// Parse the event
-	event, err := u.slackRepo.ParseEvent(r.Body)
+	event, err := u.slackRepo.ParseEvent(ctx, r.Body)
 	if err != nil {
-		slog.Error("failed to parse event", "error", err)
+		slog.ErrorContext(ctx, "failed to parse event", "error", err)
 		return model.NewStatusResponse(http.StatusBadRequest)
 	}
 	if event == nil {
 		return model.NewStatusResponse(http.StatusOK)
 	}
-	return event.Handle(u)
+	return u.handle(ctx, r, event)
 }
 
 // HandleInteraction processes incoming Slack interactions
-func (u *SlackUsecaseImpl) HandleInteraction(r *model.HTTPRequest) *model.HTTPResponse {
+func (u *SlackUsecaseImpl) HandleInteraction(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleInteraction")
+	defer span.End()
 	// Verify the request
-	if err := u.slackRepo.VerifyRequest(r); err != nil {
-		slog.Error("failed to verify request", "error", err)
+	if err := u.slackRepo.VerifyRequest(ctx, r); err != nil {
+		slog.ErrorContext(ctx, "failed to verify request", "error", err)
 		return model.NewStatusResponse(http.StatusBadRequest)
 	}
 	// Parse the interaction
-	event, err := u.slackRepo.ParseInteraction(r.Body)
+	event, err := u.slackRepo.ParseInteraction(ctx, r.Body)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to parse interaction", "error", err)
+		return model.NewStatusResponse(http.StatusBadRequest)
+	}
+	if event == nil {
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	return u.handle(ctx, r, event)
+}
+
+// HandleCommand processes incoming Slack slash commands
+func (u *SlackUsecaseImpl) HandleCommand(ctx context.Context, r *model.HTTPRequest) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleCommand")
+	defer span.End()
+	// Verify the request
+	if err := u.slackRepo.VerifyRequest(ctx, r); err != nil {
+		slog.ErrorContext(ctx, "failed to verify request", "error", err)
+		return model.NewStatusResponse(http.StatusBadRequest)
+	}
+	// Parse the command
+	event, err := u.slackRepo.ParseCommand(ctx, r.Body)
 	if err != nil {
-		slog.Error("failed to parse interaction", "error", err)
+		slog.ErrorContext(ctx, "failed to parse command", "error", err)
 		return model.NewStatusResponse(http.StatusBadRequest)
 	}
 	if event == nil {
 		return model.NewStatusResponse(http.StatusOK)
 	}
-	return event.Handle(u)
+	return u.handle(ctx, r, event)
+}
+
+// handle dispatches the event unless an earlier delivery of it has already been handled
+func (u *SlackUsecaseImpl) handle(ctx context.Context, r *model.HTTPRequest, event model.Event) *model.HTTPResponse {
+	ctx = withEventLogContext(ctx, event)
+	idempotent, ok := event.(model.IdempotentEvent)
+	if !ok || idempotent.IdempotencyKey() == "" {
+		return event.Handle(ctx, u)
+	}
+	key := idempotent.IdempotencyKey()
+	retryNum := http.Header(r.Headers).Get("X-Slack-Retry-Num")
+	claimed, err := u.idempotencyRepo.Claim(key)
+	if err != nil {
+		// Handling an event twice is better than not handling it at all
+		slog.WarnContext(ctx, "failed to claim idempotency key", "key", key, "error", err)
+		return event.Handle(ctx, u)
+	}
+	if !claimed {
+		slog.InfoContext(ctx, "skipping already handled event", "key", key, "retry_num", retryNum)
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	// Handling a mention may take longer than Slack waits, so answer before doing the slow part
+	// rather than have Slack give up and retry while the first delivery is still running
+	if u.options.FastAck {
+		if mention, ok := event.(*model.AppMentionEvent); ok {
+			if response, ok := u.enqueueAppMention(ctx, mention); ok {
+				return response
+			}
+		}
+	}
+	response := event.Handle(ctx, u)
+	if response.StatusCode >= http.StatusInternalServerError {
+		// Let Slack's retry handle the event again
+		if err := u.idempotencyRepo.Release(key); err != nil {
+			slog.WarnContext(ctx, "failed to release idempotency key", "key", key, "error", err)
+		}
+	}
+	return response
+}
+
+// withEventLogContext attaches the Slack workspace, channel and user the event came from to the log lines of ctx
+func withEventLogContext(ctx context.Context, event model.Event) context.Context {
+	switch e := event.(type) {
+	case *model.AppMentionEvent:
+		return logging.WithSlack(ctx, e.TeamID, e.ChannelID, string(e.MemberID))
+	case *model.InteractiveMessageEvent:
+		return logging.WithSlack(ctx, e.TeamID, e.ChannelID, string(e.MemberID))
+	case *model.ReactionAddedEvent:
+		return logging.WithSlack(ctx, e.TeamID, e.ChannelID, string(e.MemberID))
+	case *model.SlashCommandEvent:
+		return logging.WithSlack(ctx, e.TeamID, e.ChannelID, string(e.MemberID))
+	default:
+		return ctx
+	}
 }
@@ -1,38 +1,233 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// jobTypeInteractiveAction is the job type of interactive actions processed in the background
+	jobTypeInteractiveAction = "interactive_action"
+	// jobTypeAppMention is the job type of app mentions acknowledged before being handled
+	jobTypeAppMention = "app_mention"
 )
 
 // HandleAppMention handles app mention events
-func (u *SlackUsecaseImpl) HandleAppMention(event *model.AppMentionEvent) *model.HTTPResponse {
-	return u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
+func (u *SlackUsecaseImpl) HandleAppMention(ctx context.Context, event *model.AppMentionEvent) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleAppMention", trace.WithAttributes(
+		attribute.String("slack.channel_id", event.ChannelID),
+		attribute.String("slack.thread_ts", event.ThreadTS),
+	))
+	defer span.End()
+	if command, args := parseMentionCommand(event.Text); command == "audit" {
+		if channelID, threadTS, ok := auditCommandTarget(event, args); ok {
+			return u.handleAuditCommand(ctx, event, channelID, threadTS)
+		}
+	}
+	// Remember the pull request to review, linked to in the mention or the message starting the thread
+	externalRef := u.findPullRequestURL(ctx, event.ChannelID, event.ThreadTS, event.Text)
+	return u.sendReviewerSelectionMessage(ctx, event.ChannelID, event.ThreadTS, event.MemberID, externalRef)
+}
+
+// enqueueAppMention schedules the app mention to be handled in the background.
+// It reports false when the event could not be enqueued and must be handled synchronously.
+func (u *SlackUsecaseImpl) enqueueAppMention(ctx context.Context, event *model.AppMentionEvent) (*model.HTTPResponse, bool) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal app mention", "error", err)
+		return nil, false
+	}
+	if err := u.jobQueue.Enqueue(ctx, jobTypeAppMention, payload); err != nil {
+		slog.ErrorContext(ctx, "failed to enqueue app mention", "error", err)
+		return nil, false
+	}
+	slog.InfoContext(ctx, "acknowledged app mention before handling it", "event_id", event.EventID)
+	return model.NewStatusResponse(http.StatusOK), true
+}
+
+// handleAppMentionJob handles an app mention enqueued by enqueueAppMention
+func (u *SlackUsecaseImpl) handleAppMentionJob(ctx context.Context, job *model.Job) error {
+	var event model.AppMentionEvent
+	if err := json.Unmarshal(job.Payload, &event); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal app mention", "error", err)
+		// Retrying cannot fix a malformed payload
+		return nil
+	}
+	ctx = withEventLogContext(ctx, &event)
+	response := u.HandleAppMention(ctx, &event)
+	if response.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
+	if job.IsLastAttempt() {
+		// Slack has its answer and will not deliver the mention again, so tell the member rather than drop it silently
+		u.abandonAppMention(ctx, &event)
+	}
+	return fmt.Errorf("app mention failed with status %d", response.StatusCode)
+}
+
+// abandonAppMention tells the thread that the mention could not be handled and forgets that it was,
+// so that a redelivery of it would be handled again
+func (u *SlackUsecaseImpl) abandonAppMention(ctx context.Context, event *model.AppMentionEvent) {
+	if key := event.IdempotencyKey(); key != "" {
+		if err := u.idempotencyRepo.Release(key); err != nil {
+			slog.WarnContext(ctx, "failed to release idempotency key", "key", key, "error", err)
+		}
+	}
+	l := u.localizer(ctx, event.ChannelID, event.MemberID)
+	message := model.NewMessage(event.ChannelID, l.T("mention.failed", "<@"+string(event.MemberID)+">"), nil, false, event.ThreadTS)
+	if _, err := u.slackRepo.PostMessage(ctx, message); err != nil {
+		slog.ErrorContext(ctx, "failed to tell thread that the app mention failed", "error", err)
+	}
 }
 
 // HandleInteractiveMessage handles interactive message events
-func (u *SlackUsecaseImpl) HandleInteractiveMessage(event *model.InteractiveMessageEvent) *model.HTTPResponse {
-	// Delete the original message synchronously to provide immediate feedback
-	if err := u.slackRepo.DeleteMessage(event.ChannelID, event.MessageTS); err != nil {
-		slog.Error("failed to delete message", "error", err)
-		return model.NewStatusResponse(http.StatusInternalServerError)
+func (u *SlackUsecaseImpl) HandleInteractiveMessage(ctx context.Context, event *model.InteractiveMessageEvent) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleInteractiveMessage", trace.WithAttributes(interactionAttributes(event)...))
+	defer span.End()
+	l := u.localizer(ctx, event.ChannelID, event.MemberID)
+	// Make sure that only one of several concurrent interactions on the review assigns a reviewer
+	if mode, ok := model.AssignmentModeFromActionID(event.ActionID); ok {
+		clicked := model.NewAuditEvent(model.AuditEventClicked, event.ChannelID, event.ThreadTS, event.MemberID, time.Now())
+		clicked.Mode = mode
+		review, claimed, err := u.claimReview(ctx, event, mode)
+		if err != nil {
+			// Assigning without a claim is better than not assigning at all
+			slog.WarnContext(ctx, "failed to claim review", "error", err)
+		} else if !claimed {
+			slog.InfoContext(ctx, "review already claimed", "review_id", review.ID, "claimed_by", review.ClaimedBy, "member_id", event.MemberID)
+			clicked.Detail = "already claimed by " + string(review.ClaimedBy)
+			u.recordAudit(ctx, clicked)
+			return u.alreadyClaimedResponse(ctx, l, review)
+		}
+		u.recordAudit(ctx, clicked)
+	}
+	// Replace the original message synchronously to provide immediate feedback.
+	// Doing so before enqueueing keeps it from overwriting the result of the job.
+	placeholder := &model.Message{
+		ChannelID:       event.ChannelID,
+		Text:            l.T("selection.assigning"),
+		ReplaceOriginal: true,
+		ThreadTS:        event.ThreadTS,
+	}
+	if err := u.slackRepo.ReplaceMessage(ctx, placeholder, event.MessageTS, event.ResponseURL); err != nil {
+		// The job replaces the message again, so the action can still be processed
+		slog.WarnContext(ctx, "failed to replace message", "error", err)
 	}
 	// Process the action asynchronously
-	go u.processInteractiveAction(event)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal interactive action", "error", err)
+		u.restoreReviewerSelectionMessage(ctx, event)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	if err := u.jobQueue.Enqueue(ctx, jobTypeInteractiveAction, payload); err != nil {
+		slog.ErrorContext(ctx, "failed to enqueue interactive action", "error", err)
+		// Give the message back so that it can be used again
+		u.restoreReviewerSelectionMessage(ctx, event)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
 	// Return immediately to avoid Slack timeout
 	return model.NewStatusResponse(http.StatusOK)
 }
 
-// sendReviewerSelectionMessage sends the reviewer selection message (same as HandleAppMention)
-func (u *SlackUsecaseImpl) sendReviewerSelectionMessage(channelID, threadTS string) *model.HTTPResponse {
+// handleInteractiveActionJob runs an interactive action enqueued by HandleInteractiveMessage
+func (u *SlackUsecaseImpl) handleInteractiveActionJob(ctx context.Context, job *model.Job) error {
+	var event model.InteractiveMessageEvent
+	if err := json.Unmarshal(job.Payload, &event); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal interactive action", "error", err)
+		// Retrying cannot fix a malformed payload
+		return nil
+	}
+	ctx = withEventLogContext(ctx, &event)
+	err := u.processInteractiveAction(ctx, &event)
+	if err != nil && job.IsLastAttempt() {
+		// Give the user the selection message back so that they can try again
+		u.restoreReviewerSelectionMessage(ctx, &event)
+	}
+	return err
+}
+
+// sendReviewerSelectionMessage posts the reviewer selection message in the thread of the review request
+func (u *SlackUsecaseImpl) sendReviewerSelectionMessage(
+	ctx context.Context,
+	channelID, threadTS string,
+	requesterID model.MemberID,
+	externalRef string,
+) *model.HTTPResponse {
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	l := u.localizer(ctx, channelID, requesterID)
+	review, opened, err := u.openReview(channelID, threadTS, requesterID, externalRef)
+	if err != nil {
+		// The selection message still works without the review, which the interaction then creates
+		slog.ErrorContext(ctx, "failed to open review", "channel", channelID, "thread_ts", threadTS, "error", err)
+	} else if !opened {
+		// Another selection message would undo the assignment and keep the reviewer from completing the review
+		slog.InfoContext(ctx, "review already taken", "review_id", review.ID, "status", review.Status)
+		if _, err := u.slackRepo.PostMessage(ctx, model.NewMessage(channelID, reviewTakenText(l, review), nil, false, threadTS)); err != nil {
+			slog.ErrorContext(ctx, "failed to post review status", "error", err)
+			return model.NewStatusResponse(http.StatusInternalServerError)
+		}
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	codeOwners := u.codeOwners(ctx, roster, u.getPullRequest(ctx, externalRef), requesterID)
+	message := u.newReviewerSelectionMessage(l, channelID, threadTS, roster.Available(time.Now()), codeOwners)
+	// Post the message to Slack
+	if _, err := u.slackRepo.PostMessage(ctx, message); err != nil {
+		slog.ErrorContext(ctx, "failed to post reviewer selection message", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	u.recordAudit(ctx, model.NewAuditEvent(model.AuditEventSelectionPosted, channelID, threadTS, requesterID, time.Now()))
+	return model.NewStatusResponse(http.StatusOK)
+}
+
+// restoreReviewerSelectionMessage turns the message of a failed interaction back into the reviewer selection message
+// so that the user can try again
+func (u *SlackUsecaseImpl) restoreReviewerSelectionMessage(ctx context.Context, event *model.InteractiveMessageEvent) {
+	u.reopenReview(ctx, event.ChannelID, event.ThreadTS, "", "")
+	available := model.ReviewerMap{}
+	var codeOwners []model.Member
+	if roster, err := u.reviewerRepo.GetRoster(); err != nil {
+		// The buttons still work without the reviewers to select from
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+	} else {
+		available = roster.Available(time.Now())
+		pullRequest := u.getPullRequest(ctx, u.reviewExternalRef(ctx, event.ChannelID, event.ThreadTS))
+		codeOwners = u.codeOwners(ctx, roster, pullRequest, "")
+	}
+	message := u.newReviewerSelectionMessage(u.localizer(ctx, event.ChannelID, event.MemberID), event.ChannelID, event.ThreadTS, available, codeOwners)
+	message.ReplaceOriginal = true
+	if err := u.slackRepo.ReplaceMessage(ctx, message, event.MessageTS, event.ResponseURL); err != nil {
+		slog.ErrorContext(ctx, "failed to restore reviewer selection message", "error", err)
+		return
+	}
+	restored := model.NewAuditEvent(model.AuditEventSelectionPosted, event.ChannelID, event.ThreadTS, "", time.Now())
+	restored.Detail = "restored after the " + event.ActionID + " action could not be completed"
+	u.recordAudit(ctx, restored)
+}
+
+// newReviewerSelectionMessage creates the message for choosing how to assign one of the reviewers,
+// suggesting the code owners of the pull request under review if there are any
+func (u *SlackUsecaseImpl) newReviewerSelectionMessage(l *i18n.Localizer, channelID, threadTS string, reviewers model.ReviewerMap, codeOwners []model.Member) *model.Message {
 	// Create options for the select menu
 	options := make([]struct {
 		Text  string `json:"text"`
 		Value string `json:"value"`
-	}, 0, len(u.reviewerMap))
-	for displayName := range u.reviewerMap {
+	}, 0, len(reviewers))
+	for displayName := range reviewers {
 		options = append(options, struct {
 			Text  string `json:"text"`
 			Value string `json:"value"`
@@ -41,130 +236,229 @@ func (u *SlackUsecaseImpl) sendReviewerSelectionMessage(channelID, threadTS stri
 			Value: displayName,
 		})
 	}
-	message := model.NewMessage(
+	text := l.T("selection.instructions", l.T("button.random"), l.T("button.urgent"))
+	actions := []model.Action{
+		{
+			Name:  "random_reviewer",
+			Text:  l.T("button.random"),
+			Type:  "button",
+			Value: "",
+		},
+		{
+			Name:  "urgent_reviewer",
+			Text:  l.T("button.urgent"),
+			Type:  "button",
+			Value: "",
+		},
+	}
+	if len(codeOwners) > 0 {
+		names := make([]string, len(codeOwners))
+		for i, owner := range codeOwners {
+			names[i] = owner.DisplayName
+		}
+		text += "\n" + l.Plural("selection.code_owners", len(names), l.List(names), l.T("button.suggested"))
+		actions = append(actions, model.Action{
+			Name:  "suggested_reviewer",
+			Text:  l.T("button.suggested"),
+			Type:  "button",
+			Value: "",
+		})
+	}
+	actions = append(actions, model.Action{
+		Name:    "select_reviewer",
+		Text:    l.T("select.reviewer"),
+		Type:    "select",
+		Options: options,
+	})
+	return model.NewMessage(
 		channelID,
-		"レビュワーを選択してください",
+		l.T("selection.text"),
 		[]model.Attachment{
 			{
-				Text:       "ランダムに指定したい場合は「Random」を、急ぎの場合は「Urgent」を選択してください",
+				Text:       text,
 				CallbackID: "reviewer_selection",
-				Actions: []model.Action{
-					{
-						Name:  "random_reviewer",
-						Text:  "Random",
-						Type:  "button",
-						Value: "",
-					},
-					{
-						Name:  "urgent_reviewer",
-						Text:  "Urgent",
-						Type:  "button",
-						Value: "",
-					},
-					{
-						Name:    "select_reviewer",
-						Text:    "レビュワーを選択",
-						Type:    "select",
-						Options: options,
-					},
-				},
+				Actions:    actions,
 			},
 		},
 		false,
 		threadTS,
 	)
-	// Post the message to Slack
-	if err := u.slackRepo.PostMessage(message); err != nil {
-		slog.Error("failed to post fallback message", "error", err)
-		return model.NewStatusResponse(http.StatusInternalServerError)
+}
+
+// processInteractiveAction handles interactive action processing asynchronously.
+// It returns an error only for failures that are worth retrying.
+func (u *SlackUsecaseImpl) processInteractiveAction(ctx context.Context, event *model.InteractiveMessageEvent) (err error) {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.processInteractiveAction", trace.WithAttributes(interactionAttributes(event)...))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	mode, ok := model.AssignmentModeFromActionID(event.ActionID)
+	if !ok {
+		slog.ErrorContext(ctx, "unknown action ID", "action_id", event.ActionID)
+		u.restoreReviewerSelectionMessage(ctx, event)
+		return nil
 	}
-	return model.NewStatusResponse(http.StatusOK)
+	// Read the roster for every action so that changes through the reviewer API apply right away
+	roster, err := u.reviewerRepo.GetRoster()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get reviewer roster", "error", err)
+		return err
+	}
+	externalRef := u.reviewExternalRef(ctx, event.ChannelID, event.ThreadTS)
+	pullRequest := u.getPullRequest(ctx, externalRef)
+	choice := reviewerChoice{
+		Mode:        mode,
+		RequesterID: event.MemberID,
+		Name:        event.Value,
+	}
+	if mode == model.AssignmentModeSuggested {
+		choice.FilterMemberIDs = codeOwnerFilter(nil, u.codeOwners(ctx, roster, pullRequest, event.MemberID))
+	}
+	// Record what the assignment chose from for the audit log
+	assignment := model.NewAuditEvent(model.AuditEventAssigned, event.ChannelID, event.ThreadTS, event.MemberID, time.Now())
+	reviewer, ok, err := u.chooseReviewer(ctx, roster, choice, assignment)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		u.restoreReviewerSelectionMessage(ctx, event)
+		return nil
+	}
+	// Replace the message that was interacted with, keeping a single message per review request
+	l := u.localizer(ctx, event.ChannelID, reviewer.MemberID)
+	message := newAssignmentMessage(l, event.ChannelID, event.ThreadTS, reviewer, mode, pullRequest)
+	message.ReplaceOriginal = true
+	if err := u.slackRepo.ReplaceMessage(ctx, message, event.MessageTS, event.ResponseURL); err != nil {
+		slog.ErrorContext(ctx, "failed to replace message", "error", err)
+		return err
+	}
+	// Slack does not notify about mentions added by editing a message, so the reviewer is mentioned in a new one
+	notification := model.NewMessage(event.ChannelID, l.T("assignment.notification", "<@"+string(reviewer.MemberID)+">"), nil, false, event.ThreadTS)
+	if _, err := u.slackRepo.PostMessage(ctx, notification); err != nil {
+		// The assignment stands; retrying would assign another reviewer
+		slog.ErrorContext(ctx, "failed to notify reviewer", "reviewer", reviewer.DisplayName, "error", err)
+	}
+	u.assignReview(ctx, event.ChannelID, event.ThreadTS, reviewer)
+	u.requestCodeHostReview(ctx, roster, externalRef, reviewer, assignment.PreviousReviewer)
+	assignment.Reviewer = &reviewer
+	u.recordAudit(ctx, assignment)
+	return nil
+}
+
+// reviewerChoice describes how a reviewer is to be chosen
+type reviewerChoice struct {
+	Mode model.AssignmentMode
+	// RequesterID is never chosen
+	RequesterID model.MemberID
+	// Name is the reviewer picked in select mode, or the current reviewer in reassign mode
+	Name string
+	// FilterMemberIDs restricts the random modes to these members when given,
+	// and are the code owners to choose from in suggested mode
+	FilterMemberIDs []model.MemberID
 }
 
-// processInteractiveAction handles interactive action processing asynchronously
-func (u *SlackUsecaseImpl) processInteractiveAction(event *model.InteractiveMessageEvent) {
-	var reviewerName string
-	var messageText string
-	switch event.ActionID {
-	case "random_reviewer":
+// chooseReviewer chooses an available reviewer of the roster the way the mode asks for,
+// filling in the mode, the candidates and the excluded members of the assignment audit event.
+// It returns false when there is no reviewer to choose, and an error only for failures that are worth retrying.
+func (u *SlackUsecaseImpl) chooseReviewer(
+	ctx context.Context,
+	roster *model.Roster,
+	choice reviewerChoice,
+	assignment *model.AuditEvent,
+) (model.Member, bool, error) {
+	// Paused reviewers are not assigned but keep their names
+	reviewers, available := roster.ReviewerMap(), roster.Available(time.Now())
+	assignment.Mode = choice.Mode
+	switch choice.Mode {
+	case model.AssignmentModeRandom:
 		// Get random reviewer from configured map, excluding the requesting user
-		reviewer, ok := u.reviewerMap.GetRandomReviewer(nil, []model.MemberID{event.MemberID})
-		if !ok {
-			slog.Error("no reviewers configured")
-			u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-			return
-		}
-		reviewerName = reviewer.DisplayName
-		reviewerID := u.reviewerMap[reviewerName]
-		messageText = "<@" + string(reviewerID) + ">\n【ランダム】\nこのメッセージをレビューし、完了したら :white_check_mark: のリアクションをつけてください。\nメッセージ内のリンクは *シークレットウィンドウ* で開いて確認するようにしてください。"
-	case "urgent_reviewer":
+		assignment.Excluded = []model.MemberID{choice.RequesterID}
+	case model.AssignmentModeUrgent:
 		// Get all reviewer member IDs from the map
 		var allReviewerIDs []model.MemberID
-		for _, memberID := range u.reviewerMap {
-			allReviewerIDs = append(allReviewerIDs, memberID)
+		for _, member := range available.Candidates(choice.FilterMemberIDs, nil) {
+			allReviewerIDs = append(allReviewerIDs, member.MemberID)
 		}
 		// Filter to get online member IDs from all reviewers
-		onlineMemberIDs, err := u.slackRepo.FilterOnlineMemberIDs(allReviewerIDs)
+		onlineMemberIDs, err := u.slackRepo.FilterOnlineMemberIDs(ctx, allReviewerIDs)
 		if err != nil {
-			slog.Error("failed to filter online member IDs", "error", err)
-			u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-			return
+			slog.ErrorContext(ctx, "failed to filter online member IDs", "error", err)
+			return model.Member{}, false, err
 		}
-		// Get random online reviewer from configured map, excluding the requesting user
-		reviewer, ok := u.reviewerMap.GetRandomReviewer(onlineMemberIDs, []model.MemberID{event.MemberID})
-		if !ok {
-			slog.Error("no reviewers configured")
-			u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-			return
+		// Get random online reviewer from configured map, excluding the requesting user.
+		// When nobody is online, any of the reviewers will do.
+		if len(onlineMemberIDs) > 0 {
+			choice.FilterMemberIDs = onlineMemberIDs
 		}
-		reviewerName = reviewer.DisplayName
-		reviewerID := u.reviewerMap[reviewerName]
-		messageText = "<@" + string(reviewerID) + ">\n【急ぎ】\nこのメッセージをレビューし、完了したら :white_check_mark: のリアクションをつけてください。\nメッセージ内のリンクは *シークレットウィンドウ* で開いて確認するようにしてください。"
-	case "select_reviewer":
-		reviewerName = event.Value
-		reviewerID := u.reviewerMap[reviewerName]
-		messageText = "<@" + string(reviewerID) + ">\n【選択】\nこのメッセージをレビューし、完了したら :white_check_mark: のリアクションをつけてください。\nメッセージ内のリンクは *シークレットウィンドウ* で開いて確認するようにしてください。"
-	case "reassign_reviewer":
-		// Get current reviewer name from Value field
-		currentReviewerName := event.Value
-		// Get current reviewer ID
-		currentReviewerID := u.reviewerMap[currentReviewerName]
-		// Get random reviewer excluding the current reviewer and the requesting user
-		excludeMembers := []model.MemberID{currentReviewerID, event.MemberID}
-		reviewer, ok := u.reviewerMap.GetRandomReviewer(nil, excludeMembers)
-		if !ok {
-			slog.Error("no other reviewers available")
-			u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-			return
+		assignment.Excluded = []model.MemberID{choice.RequesterID}
+	case model.AssignmentModeSuggested:
+		// Unlike the other random modes, suggested mode does not fall back to all reviewers
+		if len(choice.FilterMemberIDs) == 0 {
+			slog.ErrorContext(ctx, "no code owners available")
+			return model.Member{}, false, nil
 		}
-		reviewerName = reviewer.DisplayName
-		reviewerID := u.reviewerMap[reviewerName]
-		messageText = "<@" + string(reviewerID) + ">\n【ランダム】\nこのメッセージをレビューし、完了したら :white_check_mark: のリアクションをつけてください。\nメッセージ内のリンクは *シークレットウィンドウ* で開いて確認するようにしてください。"
-	default:
-		slog.Error("unknown action ID", "action_id", event.ActionID)
-		u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-		return
+		assignment.Excluded = []model.MemberID{choice.RequesterID}
+	case model.AssignmentModeSelect:
+		reviewerID := reviewers[choice.Name]
+		if reviewerID == "" {
+			slog.ErrorContext(ctx, "selected reviewer is no longer configured", "reviewer", choice.Name)
+			return model.Member{}, false, nil
+		}
+		return model.Member{DisplayName: choice.Name, MemberID: reviewerID}, true, nil
+	case model.AssignmentModeReassign:
+		// Get random reviewer excluding the current reviewer and the requesting user
+		currentReviewer := model.Member{DisplayName: choice.Name, MemberID: reviewers[choice.Name]}
+		assignment.Type = model.AuditEventReassigned
+		assignment.PreviousReviewer = &currentReviewer
+		assignment.Excluded = []model.MemberID{currentReviewer.MemberID, choice.RequesterID}
+	}
+	assignment.Candidates = model.MemberIDs(available.Candidates(choice.FilterMemberIDs, assignment.Excluded))
+	reviewer, ok := available.GetRandomReviewer(choice.FilterMemberIDs, assignment.Excluded)
+	if !ok {
+		slog.ErrorContext(ctx, "no reviewers available", "mode", choice.Mode)
+		return model.Member{}, false, nil
 	}
+	return reviewer, true, nil
+}
+
+// assignmentLabels are the messages labeling the assignment modes in the assignment message
+var assignmentLabels = map[model.AssignmentMode]string{
+	model.AssignmentModeRandom:    "assignment.random",
+	model.AssignmentModeUrgent:    "assignment.urgent",
+	model.AssignmentModeSelect:    "assignment.select",
+	model.AssignmentModeReassign:  "assignment.random",
+	model.AssignmentModeSuggested: "assignment.suggested",
+}
 
+// newAssignmentMessage creates the message telling the reviewer about the review, with a button to reassign it.
+// The pull request under review is described as well if known.
+func newAssignmentMessage(l *i18n.Localizer, channelID, threadTS string, reviewer model.Member, mode model.AssignmentMode, pullRequest *model.PullRequest) *model.Message {
+	messageText := "<@" + string(reviewer.MemberID) + ">\n" + l.T(assignmentLabels[mode]) + "\n" + l.T("assignment.instructions")
 	fields := []model.AttachmentField{
 		{
-			Title: "レビュワー",
-			Value: reviewerName,
+			Title: l.T("assignment.reviewer"),
+			Value: reviewer.DisplayName,
 			Short: false,
 		},
 	}
+	if pullRequest != nil {
+		fields = append(fields, pullRequestFields(l, pullRequest)...)
+	}
 	// Create Reassign button action
 	actions := []model.Action{
 		{
 			Name:  "reassign_reviewer",
-			Text:  "Reassign",
+			Text:  l.T("button.reassign"),
 			Type:  "button",
-			Value: reviewerName,
+			Value: reviewer.DisplayName,
 		},
 	}
-	// Create and post new message in the thread
-	message := model.NewMessage(
-		event.ChannelID,
+	return model.NewMessage(
+		channelID,
 		messageText,
 		[]model.Attachment{
 			{
@@ -175,17 +469,43 @@ func (u *SlackUsecaseImpl) processInteractiveAction(event *model.InteractiveMess
 			},
 		},
 		false,
-		event.ThreadTS,
+		threadTS,
 	)
-	// Post the new message
-	if err := u.slackRepo.PostMessage(message); err != nil {
-		slog.Error("failed to post message", "error", err)
-		u.sendReviewerSelectionMessage(event.ChannelID, event.ThreadTS)
-		return
+}
+
+// HandleReactionAdded completes the review when its reviewer adds ✅ to the review request
+func (u *SlackUsecaseImpl) HandleReactionAdded(ctx context.Context, event *model.ReactionAddedEvent) *model.HTTPResponse {
+	if event.Reaction != "white_check_mark" {
+		return model.NewStatusResponse(http.StatusOK)
 	}
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleReactionAdded", trace.WithAttributes(
+		attribute.String("slack.channel_id", event.ChannelID),
+		attribute.String("slack.message_ts", event.MessageTS),
+	))
+	defer span.End()
+	review, completed := u.completeReview(ctx, event.ChannelID, event.MessageTS, event.MemberID)
+	if !completed {
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	slog.InfoContext(ctx, "review completed", "review_id", review.ID, "reviewer", review.Reviewer.DisplayName)
+	audit := model.NewAuditEvent(model.AuditEventCompleted, event.ChannelID, event.MessageTS, event.MemberID, review.CompletedAt)
+	audit.Mode = review.Mode
+	audit.Reviewer = &review.Reviewer
+	u.recordAudit(ctx, audit)
+	return model.NewStatusResponse(http.StatusOK)
 }
 
 // HandleURLVerification handles URL verification events
-func (u *SlackUsecaseImpl) HandleURLVerification(event *model.URLVerificationEvent) *model.HTTPResponse {
+func (u *SlackUsecaseImpl) HandleURLVerification(_ context.Context, event *model.URLVerificationEvent) *model.HTTPResponse {
 	return model.NewTextResponse(http.StatusOK, []byte(event.Challenge))
 }
+
+// interactionAttributes returns the span attributes describing an interaction
+func interactionAttributes(event *model.InteractiveMessageEvent) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("slack.channel_id", event.ChannelID),
+		attribute.String("slack.thread_ts", event.ThreadTS),
+		attribute.String("slack.action_id", event.ActionID),
+		attribute.String("slack.user_id", string(event.MemberID)),
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+// fakeMessages is a Slack workspace remembering the messages posted to it
+type fakeMessages struct {
+	repository.SlackRepository
+	posted []*model.Message
+}
+
+func (f *fakeMessages) PostMessage(_ context.Context, message *model.Message) (string, error) {
+	f.posted = append(f.posted, message)
+	return "2.0", nil
+}
+
+// fakeIdempotency remembers the keys claimed and not released
+type fakeIdempotency map[string]bool
+
+func (f fakeIdempotency) Claim(key string) (bool, error) {
+	if f[key] {
+		return false, nil
+	}
+	f[key] = true
+	return true, nil
+}
+
+func (f fakeIdempotency) Release(key string) error {
+	delete(f, key)
+	return nil
+}
+
+func newTestCatalog(t *testing.T) *i18n.Catalog {
+	t.Helper()
+	catalog, err := i18n.NewCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return catalog
+}
+
+func TestAbandonAppMention(t *testing.T) {
+	slackRepo := &fakeMessages{}
+	idempotency := fakeIdempotency{"event:Ev1": true}
+	u := &SlackUsecaseImpl{
+		slackRepo:       slackRepo,
+		idempotencyRepo: idempotency,
+		catalog:         newTestCatalog(t),
+		options:         SlackUsecaseOptions{Language: i18n.English},
+	}
+	event := &model.AppMentionEvent{EventID: "Ev1", ChannelID: "C1", ThreadTS: "1.0", MemberID: "U1"}
+
+	u.abandonAppMention(context.Background(), event)
+	if idempotency["event:Ev1"] {
+		t.Error("the idempotency key of the mention is still claimed")
+	}
+	if len(slackRepo.posted) != 1 {
+		t.Fatalf("posted %d messages, want 1", len(slackRepo.posted))
+	}
+	message := slackRepo.posted[0]
+	if message.ChannelID != "C1" || message.ThreadTS != "1.0" || !strings.HasPrefix(message.Text, "<@U1> ") {
+		t.Errorf("posted %+v, want a reply to U1 in the thread", message)
+	}
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/himura467/slack-review-request-bot/internal/domain/model"
+	"github.com/himura467/slack-review-request-bot/internal/domain/repository"
+	"github.com/himura467/slack-review-request-bot/internal/i18n"
+)
+
+type StatsUsecase interface {
+	// GetStats returns the statistics of the review requests made in the period as JSON
+	GetStats(ctx context.Context, period model.StatsPeriod) *model.HTTPResponse
+}
+
+type StatsUsecaseImpl struct {
+	auditRepo repository.AuditRepository
+}
+
+var _ StatsUsecase = (*StatsUsecaseImpl)(nil)
+
+func NewStatsUsecase(auditRepo repository.AuditRepository) *StatsUsecaseImpl {
+	return &StatsUsecaseImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+func (u *StatsUsecaseImpl) GetStats(ctx context.Context, period model.StatsPeriod) *model.HTTPResponse {
+	stats, err := u.Compute(period)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to compute review stats", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	body, err := json.Marshal(stats)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal review stats", "error", err)
+		return model.NewStatusResponse(http.StatusInternalServerError)
+	}
+	return model.NewJSONResponse(http.StatusOK, body)
+}
+
+// Compute computes the statistics of the review requests made in the period from the audit log
+func (u *StatsUsecaseImpl) Compute(period model.StatsPeriod) (*model.ReviewStats, error) {
+	return computeReviewStats(u.auditRepo, period)
+}
+
+// computeReviewStats reads the audit log from the start of the period on, so that later completions of requests
+// made in the period count as well
+func computeReviewStats(auditRepo repository.AuditRepository, period model.StatsPeriod) (*model.ReviewStats, error) {
+	events, err := auditRepo.List(model.AuditQuery{From: period.From})
+	if err != nil {
+		return nil, err
+	}
+	return model.NewReviewStats(events, period), nil
+}
+
+// HandleSlashCommand answers slash commands with a message only the invoking member can see
+func (u *SlackUsecaseImpl) HandleSlashCommand(ctx context.Context, event *model.SlashCommandEvent) *model.HTTPResponse {
+	ctx, span := tracer.Start(ctx, "SlackUsecase.HandleSlashCommand")
+	defer span.End()
+	l := u.localizer(ctx, event.ChannelID, event.MemberID)
+	fields := strings.Fields(event.Text)
+	if len(fields) == 0 || fields[0] != "stats" || len(fields) > 2 {
+		return ephemeralResponse(ctx, l.T("stats.usage"))
+	}
+	var periodText string
+	if len(fields) == 2 {
+		periodText = fields[1]
+	}
+	period, err := model.ParseStatsPeriod(periodText, time.Now())
+	if err != nil {
+		return ephemeralResponse(ctx, l.T("stats.usage"))
+	}
+	stats, err := computeReviewStats(u.auditRepo, period)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to compute review stats", "error", err)
+		return ephemeralResponse(ctx, l.T("stats.failed"))
+	}
+	return ephemeralResponse(ctx, formatReviewStats(l, stats, u.allReviewers(ctx)))
+}
+
+// ephemeralResponse answers the request with a message only the invoking member can see
+func ephemeralResponse(ctx context.Context, text string) *model.HTTPResponse {
+	body, err := json.Marshal(model.NewEphemeralMessage(text))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal ephemeral message", "error", err)
+		return model.NewStatusResponse(http.StatusOK)
+	}
+	return model.NewJSONResponse(http.StatusOK, body)
+}
+
+// formatReviewStats formats the statistics as a Slack message, naming members by their names among the reviewers
+func formatReviewStats(l *i18n.Localizer, stats *model.ReviewStats, reviewers model.ReviewerMap) string {
+	var b strings.Builder
+	// Slack shows the dates in the format of each reader
+	b.WriteString(l.T("stats.title",
+		fmt.Sprintf("<!date^%d^{date_short}|%s>", stats.Period.From.Unix(), stats.Period.From.Format("2006-01-02")),
+		fmt.Sprintf("<!date^%d^{date_short}|%s>", stats.Period.To.Unix(), stats.Period.To.Format("2006-01-02"))))
+	b.WriteString("\n" + l.T("stats.counts", stats.Requested, stats.Assigned, stats.Completed))
+	b.WriteString("\n" + l.T("stats.time_to_first_response", formatDurationStats(l, stats.TimeToFirstResponse)))
+	b.WriteString("\n" + l.T("stats.time_to_completion", formatDurationStats(l, stats.TimeToCompletion)))
+	b.WriteString("\n" + l.T("stats.reassigned", formatRate(stats.Reassigned, stats.Assigned)))
+	b.WriteString("\n" + l.T("stats.urgent_hits", formatRate(stats.UrgentHits, stats.UrgentAttempts)))
+	if len(stats.People) > 0 {
+		b.WriteString("\n" + l.T("stats.people"))
+		for _, p := range stats.People {
+			name := p.DisplayName
+			if name == "" {
+				name = reviewers.NameOf(p.MemberID)
+			}
+			fmt.Fprintf(&b, "\n• %s: %d / %d / %d", name, p.Requested, p.Assigned, p.Completed)
+		}
+	}
+	return b.String()
+}
+
+// formatDurationStats formats the median and 90th percentile, or a dash without any data
+func formatDurationStats(l *i18n.Localizer, s model.DurationStats) string {
+	if s.Count == 0 {
+		return "-"
+	}
+	return l.Plural("stats.durations", s.Count, formatDuration(l, s.Median), formatDuration(l, s.P90))
+}
+
+// formatDuration formats the duration to the minute, or to the second below a minute
+func formatDuration(l *i18n.Localizer, d time.Duration) string {
+	if d < time.Minute {
+		return l.T("duration.seconds", int(d.Seconds()))
+	}
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return l.T("duration.minutes", minutes)
+	case minutes == 0:
+		return l.T("duration.hours", hours)
+	default:
+		return l.T("duration.hours_minutes", hours, minutes)
+	}
+}
+
+// formatRate formats n out of total as a percentage, or a dash without any total
+func formatRate(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%% (%d/%d)", 100*float64(n)/float64(total), n, total)
+}
@@ -12,4 +12,18 @@ var Set = wire.NewSet(
 	infrastructure.Set,
 	NewSlackUsecase,
 	wire.Bind(new(SlackUsecase), new(*SlackUsecaseImpl)),
+	wire.Bind(new(ReviewRequestUsecase), new(*SlackUsecaseImpl)),
+	wire.Bind(new(CodeHostUsecase), new(*SlackUsecaseImpl)),
+	NewHealthUsecase,
+	wire.Bind(new(HealthUsecase), new(*HealthUsecaseImpl)),
+	NewAuditUsecase,
+	wire.Bind(new(AuditUsecase), new(*AuditUsecaseImpl)),
+	NewStatsUsecase,
+	wire.Bind(new(StatsUsecase), new(*StatsUsecaseImpl)),
+	NewExportUsecase,
+	wire.Bind(new(ExportUsecase), new(*ExportUsecaseImpl)),
+	NewReviewerUsecase,
+	wire.Bind(new(ReviewerUsecase), new(*ReviewerUsecaseImpl)),
+	NewDashboardUsecase,
+	wire.Bind(new(DashboardUsecase), new(*DashboardUsecaseImpl)),
 )
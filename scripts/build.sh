@@ -9,6 +9,4 @@ docker build \
   --provenance=false \
   --progress=plain \
   --platform=linux/amd64 \
-  --build-arg SLACK_OAUTH_TOKEN="$SLACK_OAUTH_TOKEN" \
-  --build-arg SLACK_SIGNING_SECRET="$SLACK_SIGNING_SECRET" \
   -f Dockerfile -t slack-review-request-bot .
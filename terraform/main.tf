@@ -74,20 +74,126 @@ resource "google_project_service" "cloud_run_admin" {
   service = "run.googleapis.com"
 }
 
+resource "google_project_service" "filestore" {
+  project = var.google_project_id
+  service = "file.googleapis.com"
+}
+
+# The bbolt store of reviews, jobs, the roster and the audit log, on an NFS share that outlives the instances
+resource "google_filestore_instance" "store" {
+  name     = "${local.app_name}-store"
+  location = "${var.google_region}-a"
+  tier     = "BASIC_HDD"
+  file_shares {
+    name        = "store"
+    capacity_gb = 1024
+  }
+  networks {
+    network = "default"
+    modes   = ["MODE_IPV4"]
+  }
+  depends_on = [google_project_service.filestore]
+}
+
+resource "google_project_service" "secret_manager" {
+  project = var.google_project_id
+  service = "secretmanager.googleapis.com"
+}
+
+# Secret values are added out of band, e.g. `gcloud secrets versions add slack-oauth-token --data-file=-`
+resource "google_secret_manager_secret" "slack" {
+  for_each  = toset(["slack-oauth-token", "slack-signing-secret"])
+  secret_id = each.key
+  replication {
+    auto {}
+  }
+  depends_on = [google_project_service.secret_manager]
+}
+
+resource "google_secret_manager_secret_iam_member" "slack_accessor" {
+  for_each  = google_secret_manager_secret.slack
+  secret_id = each.value.id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${data.google_project.this.number}-compute@developer.gserviceaccount.com"
+}
+
 resource "google_cloud_run_v2_service" "slack_review_request_bot" {
   name                = local.app_name
   location            = var.google_region
   deletion_protection = false
   template {
+    # NFS volumes need the second generation execution environment
+    execution_environment = "EXECUTION_ENVIRONMENT_GEN2"
+    vpc_access {
+      network_interfaces {
+        network    = "default"
+        subnetwork = "default"
+      }
+      egress = "PRIVATE_RANGES_ONLY"
+    }
+    volumes {
+      name = "store"
+      nfs {
+        server = google_filestore_instance.store.networks[0].ip_addresses[0]
+        path   = "/${google_filestore_instance.store.file_shares[0].name}"
+      }
+    }
     containers {
       image = "${var.google_region}-docker.pkg.dev/${var.google_project_id}/${local.app_name}/${local.app_name}:latest"
+      resources {
+        # Keep the CPU allocated after responding so that background jobs keep running
+        cpu_idle = false
+      }
+      volume_mounts {
+        name       = "store"
+        mount_path = "/mnt/store"
+      }
+      env {
+        name  = "STORE_PATH"
+        value = "/mnt/store/bot.db"
+      }
+      env {
+        name = "SLACK_OAUTH_TOKEN"
+        value_source {
+          secret_key_ref {
+            secret  = google_secret_manager_secret.slack["slack-oauth-token"].secret_id
+            version = "latest"
+          }
+        }
+      }
+      env {
+        name = "SLACK_SIGNING_SECRET"
+        value_source {
+          secret_key_ref {
+            secret  = google_secret_manager_secret.slack["slack-signing-secret"].secret_id
+            version = "latest"
+          }
+        }
+      }
+      # Keep traffic away until the reviewer configuration, the store and the Slack token have been checked
+      startup_probe {
+        http_get {
+          path = "/readyz"
+        }
+        period_seconds    = 5
+        failure_threshold = 12
+      }
+      liveness_probe {
+        http_get {
+          path = "/healthz"
+        }
+      }
     }
     scaling {
       min_instance_count = 0
+      # bbolt allows a single process to open the store
       max_instance_count = 1
     }
   }
-  depends_on = [google_project_service.cloud_run_admin]
+  depends_on = [
+    google_project_service.cloud_run_admin,
+    google_secret_manager_secret_iam_member.slack_accessor,
+  ]
   lifecycle {
     ignore_changes = [
       template[0].containers[0].image,